@@ -4,15 +4,35 @@ import (
 	"strings"
 )
 
-// parseBasicField parses a basic field and returns its key, value, and any error
-func (p *Parser) parseBasicField(field DataField) (string, string, error) {
+// parseBasicField parses a basic field and returns its key, value, and any
+// error. Values of fields declaring a Format are converted with ParseValue.
+func (p *parseState) parseBasicField(field DataField) (string, interface{}, error) {
 	p.buffer.Reset()
 	p.currentPos++ // Skip the space after field name
+	valueStart := p.currentPos
 	for p.currentPos < len(p.message) && p.message[p.currentPos] != '-' {
 		p.buffer.WriteByte(p.message[p.currentPos])
 		p.currentPos++
 	}
 
 	value := strings.TrimSpace(p.buffer.String())
-	return field.DataItem, value, nil
+	if field.Format == "" || value == "" {
+		return field.DataItem, value, nil
+	}
+
+	typed, err := ParseValue(field.Format, value)
+	if err != nil {
+		p.pushField(field.DataItem)
+		defer p.popField()
+		for valueStart < len(p.message) && p.message[valueStart] == ' ' {
+			valueStart++
+		}
+		perr := p.errorAt(valueStart, CodeInvalidValue, err)
+		if p.Opts.Mode != ModeLenient {
+			return "", nil, perr
+		}
+		p.warnings = append(p.warnings, perr)
+		return field.DataItem, value, nil
+	}
+	return field.DataItem, typed, nil
 }
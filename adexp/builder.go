@@ -0,0 +1,48 @@
+package adexp
+
+import "fmt"
+
+// MessageSetBuilder builds a message set in code. The schemas and catalogue
+// fields added are checked and resolved like the ones of schema files, see
+// MessageSetFromJSONWithWarnings. Errors name schemas by their position,
+// e.g. Schema[1], and catalogue fields by Catalogue.
+type MessageSetBuilder struct {
+	name    string
+	schemas []StandardSchema
+	fields  []DataField
+}
+
+func NewMessageSetBuilder(name string) *MessageSetBuilder {
+	return &MessageSetBuilder{name: name}
+}
+
+// Schema adds schemas to the set
+func (b *MessageSetBuilder) Schema(schemas ...StandardSchema) *MessageSetBuilder {
+	b.schemas = append(b.schemas, schemas...)
+	return b
+}
+
+// Field adds fields to the catalogue of the set
+func (b *MessageSetBuilder) Field(fields ...DataField) *MessageSetBuilder {
+	b.fields = append(b.fields, fields...)
+	return b
+}
+
+// Build returns the message set. The builder may be modified and built again.
+func (b *MessageSetBuilder) Build() (*MessageSet, error) {
+	set, _, err := b.BuildWithWarnings()
+	return set, err
+}
+
+// BuildWithWarnings returns the message set like Build and the FRN
+// inconsistencies found
+func (b *MessageSetBuilder) BuildWithWarnings() (*MessageSet, []error, error) {
+	l := newLoader(b.name)
+	if len(b.fields) > 0 {
+		l.add("Catalogue", schemaFile{Fields: b.fields})
+	}
+	for i, schema := range b.schemas {
+		l.add(fmt.Sprintf("Schema[%d]", i), schemaFile{StandardSchema: schema})
+	}
+	return l.finish(StandardMessageSet())
+}
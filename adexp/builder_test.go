@@ -0,0 +1,39 @@
+package adexp
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_MessageSetBuilder(t *testing.T) {
+	builder := NewMessageSetBuilder("ops").
+		Field(DataField{DataItem: "CALLSIGN", Type: Basicfield, Target: "ARCID"}).
+		Schema(StandardSchema{Category: "OFP", Items: []DataField{
+			{FRN: 1, Ref: "TITLE"},
+			{FRN: 2, Ref: "CALLSIGN", Mendatory: true},
+			{FRN: 3, DataItem: "GATE", Type: Basicfield},
+		}})
+	set, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if set.Name != "ops" || set.Set["OFP"].Items[1].Target != "ARCID" {
+		t.Errorf("Expected the CALLSIGN reference to be resolved, got %+v", set.Set["OFP"])
+	}
+	fp, err := NewParser([]MessageSet{*set}).Parse("-TITLE OFP -CALLSIGN DLH4AB -GATE A12")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fp["ARCID"] != "DLH4AB" || fp["GATE"] != "A12" {
+		t.Errorf("Unexpected flight plan: %v", fp)
+	}
+
+	_, err = builder.Schema(StandardSchema{Category: "OFP", Items: []DataField{{FRN: 1, Ref: "TITLE"}}}).Build()
+	if !errors.Is(err, ErrorDuplicateCategory) {
+		t.Errorf("Expected %v, got %v", ErrorDuplicateCategory, err)
+	}
+	expected := "Schema[1]: Category: duplicate category 'OFP', also defined in Schema[0]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected message %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,230 @@
+package adexp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrorUnknownRef = errors.New("unknown catalogue field")
+var ErrorUnknownBase = errors.New("unknown base schema")
+var ErrorCircularRef = errors.New("circular reference")
+
+// Resolve replaces the fields referencing the catalogue by their definition
+// and merges the schemas declaring Extends with their base. Catalogue fields
+// and base schemas are looked up in the set first and then in the standard
+// message set. Loaders call Resolve, sets built in code have to call it
+// before use if they contain references.
+func (s *MessageSet) Resolve() error {
+	return s.resolve(StandardMessageSet(), nil)
+}
+
+// resolve resolves the set against base, which must be resolved or nil.
+// Errors are reported as SchemaError if files holds the file a catalogue
+// field ("field NAME") or schema ("schema CATEGORY") was loaded from.
+func (s *MessageSet) resolve(base *MessageSet, files map[string]string) error {
+	r := &resolver{
+		set:      s,
+		base:     base,
+		fields:   make(map[string]DataField),
+		schemas:  make(map[string]StandardSchema),
+		visiting: make(map[string]bool),
+	}
+
+	wrap := func(key string, err error) error {
+		err = fmt.Errorf("%s: %w", key, err)
+		if file, ok := files[key]; ok {
+			return &SchemaError{File: file, Err: err}
+		}
+		return err
+	}
+	for _, name := range sortedKeys(s.Catalogue) {
+		if _, err := r.catalogueField(name); err != nil {
+			return wrap("field "+name, err)
+		}
+	}
+	for _, category := range sortedKeys(s.Set) {
+		if _, err := r.schema(category); err != nil {
+			return wrap("schema "+category, err)
+		}
+	}
+
+	if s.Catalogue != nil {
+		s.Catalogue = r.fields
+	}
+	s.Set = r.schemas
+	return nil
+}
+
+// resolver caches the fields and schemas of a set resolved so far
+type resolver struct {
+	set      *MessageSet
+	base     *MessageSet
+	fields   map[string]DataField
+	schemas  map[string]StandardSchema
+	visiting map[string]bool
+}
+
+// catalogueField returns the resolved catalogue definition of name
+func (r *resolver) catalogueField(name string) (DataField, error) {
+	if field, ok := r.fields[name]; ok {
+		return field, nil
+	}
+	def, ok := r.set.Catalogue[name]
+	if !ok {
+		if r.base != nil {
+			if field, ok := r.base.Catalogue[name]; ok {
+				return field, nil
+			}
+		}
+		return DataField{}, fmt.Errorf("%w '%s'", ErrorUnknownRef, name)
+	}
+
+	key := "field " + name
+	if r.visiting[key] {
+		return DataField{}, fmt.Errorf("%w to field '%s'", ErrorCircularRef, name)
+	}
+	r.visiting[key] = true
+	defer delete(r.visiting, key)
+
+	field, err := r.field(def)
+	if err != nil {
+		return DataField{}, err
+	}
+	r.fields[name] = field
+	return field, nil
+}
+
+// field resolves the reference of a field and of its subfields
+func (r *resolver) field(field DataField) (DataField, error) {
+	if field.Ref != "" {
+		def, err := r.catalogueField(field.Ref)
+		if err != nil {
+			return DataField{}, err
+		}
+		field = mergeRef(def, field)
+	}
+	if field.Subfields == nil {
+		return field, nil
+	}
+
+	subfields := make([]DataField, len(field.Subfields))
+	for i, subfield := range field.Subfields {
+		resolved, err := r.field(subfield)
+		if err != nil {
+			return DataField{}, fmt.Errorf("field %s: %w", field.DataItem, err)
+		}
+		subfields[i] = resolved
+	}
+	field.Subfields = subfields
+	return field, nil
+}
+
+// mergeRef returns the catalogue definition def completed by the field ref
+// referencing it: its FRN, Mendatory and Target, and the DataItem,
+// Description, Format, Repeatable and Subfields it sets
+func mergeRef(def, ref DataField) DataField {
+	field := def
+	field.Ref = ""
+	field.FRN = ref.FRN
+	field.Mendatory = ref.Mendatory
+	field.Repeatable = def.Repeatable || ref.Repeatable
+	if ref.DataItem != "" {
+		field.DataItem = ref.DataItem
+	}
+	if ref.Description != "" {
+		field.Description = ref.Description
+	}
+	if ref.Format != "" {
+		field.Format = ref.Format
+	}
+	if ref.Target != "" {
+		field.Target = ref.Target
+	}
+	if ref.Subfields != nil {
+		field.Subfields = ref.Subfields
+	}
+	return field
+}
+
+// schema returns the resolved schema of category
+func (r *resolver) schema(category string) (StandardSchema, error) {
+	if schema, ok := r.schemas[category]; ok {
+		return schema, nil
+	}
+
+	key := "schema " + category
+	if r.visiting[key] {
+		return StandardSchema{}, fmt.Errorf("%w to schema '%s'", ErrorCircularRef, category)
+	}
+	r.visiting[key] = true
+	defer delete(r.visiting, key)
+
+	schema := r.set.Set[category]
+	items := make([]DataField, len(schema.Items))
+	for i, item := range schema.Items {
+		resolved, err := r.field(item)
+		if err != nil {
+			return StandardSchema{}, err
+		}
+		items[i] = resolved
+	}
+
+	if schema.Extends != "" {
+		base, err := r.baseSchema(schema.Extends, category)
+		if err != nil {
+			return StandardSchema{}, err
+		}
+		items = extendItems(base.Items, items)
+		if schema.Version == "" {
+			schema.Version = base.Version
+		}
+	}
+
+	schema.Items = items
+	r.schemas[category] = schema
+	return schema, nil
+}
+
+// baseSchema returns the schema named by the Extends of category. A schema
+// extending its own category inherits from the standard message set.
+func (r *resolver) baseSchema(name, category string) (StandardSchema, error) {
+	if _, ok := r.set.Set[name]; ok && name != category {
+		return r.schema(name)
+	}
+	if r.base != nil {
+		if schema, ok := r.base.Set[name]; ok {
+			return schema, nil
+		}
+	}
+	return StandardSchema{}, fmt.Errorf("%w '%s'", ErrorUnknownBase, name)
+}
+
+// extendItems returns the base items with the items of the same DataItem
+// replaced and the other items appended
+func extendItems(base, items []DataField) []DataField {
+	extended := make([]DataField, len(base), len(base)+len(items))
+	copy(extended, base)
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		index[item.DataItem] = i
+	}
+	for _, item := range items {
+		if i, ok := index[item.DataItem]; ok {
+			extended[i] = item
+			continue
+		}
+		index[item.DataItem] = len(extended)
+		extended = append(extended, item)
+	}
+	return extended
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package adexp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_Resolve(t *testing.T) {
+	set := MessageSet{
+		Name: "custom",
+		Catalogue: map[string]DataField{
+			"CALLSIGN": {DataItem: "CALLSIGN", Description: "Callsign", Type: Basicfield, Target: "ARCID"},
+			"GATE":     {DataItem: "GATE", Description: "Gate", Type: StructuredField, Subfields: []DataField{{FRN: 1, Ref: "ADEP"}, {FRN: 2, Ref: "STAND"}}},
+			"STAND":    {DataItem: "STAND", Description: "Stand", Type: Basicfield},
+		},
+		Set: map[string]StandardSchema{
+			"SAM": {Category: "SAM", Extends: "SAM", Items: []DataField{
+				{FRN: 2, Ref: "CALLSIGN", Mendatory: true},
+				{FRN: 4, Ref: "ADES", Mendatory: true, Description: "Destination"},
+				{FRN: 20, Ref: "GATE"},
+			}},
+			"XSM": {Category: "XSM", Version: "0.2", Extends: "SAM", Items: []DataField{
+				{FRN: 21, DataItem: "EXTRA", Type: Basicfield},
+			}},
+		},
+	}
+	if err := set.Resolve(); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	sam := set.Set["SAM"]
+	if sam.Version != "3.1" {
+		t.Errorf("Expected the version of the base schema, got %s", sam.Version)
+	}
+	ades := fieldNamed("ADES", sam.Items)
+	expected := DataField{FRN: 4, DataItem: "ADES", Description: "Destination", Type: Basicfield, Format: FormatLocation, Mendatory: true}
+	if ades == nil || !reflect.DeepEqual(*ades, expected) {
+		t.Errorf("Expected the overridden ADES %+v, got %+v", expected, ades)
+	}
+	gate := fieldNamed("GATE", sam.Items)
+	if gate == nil || len(gate.Subfields) != 2 || gate.Subfields[0].Format != FormatLocation || gate.Subfields[1].DataItem != "STAND" {
+		t.Errorf("Expected GATE with resolved subfields, got %+v", gate)
+	}
+	if fieldNamed("CALLSIGN", sam.Items) == nil || fieldNamed("CTOT", sam.Items) == nil {
+		t.Errorf("Expected SAM to hold both inherited and added fields")
+	}
+
+	xsm := set.Set["XSM"]
+	if xsm.Version != "0.2" || fieldNamed("EXTRA", xsm.Items) == nil || fieldNamed("GATE", xsm.Items) == nil {
+		t.Errorf("Expected XSM to extend the custom SAM, got %+v", xsm)
+	}
+	if set.Catalogue["GATE"].Subfields[1].Ref != "" {
+		t.Errorf("Expected the catalogue to be resolved")
+	}
+
+	fp, err := NewParser([]MessageSet{set}).Parse("-TITLE SAM -CALLSIGN AMC101 -ADES LMML -GATE -ADEP EGLL -STAND 512")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fp["ARCID"] != "AMC101" || fp["ADES"] != LocationIndicator("LMML") {
+		t.Errorf("Unexpected flight plan: %v", fp)
+	}
+}
+
+func Test_Resolve_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      MessageSet
+		expected error
+	}{
+		{
+			name: "unknown ref",
+			set: MessageSet{Set: map[string]StandardSchema{
+				"XYZ": {Category: "XYZ", Items: []DataField{{FRN: 1, Ref: "NOPE"}}},
+			}},
+			expected: ErrorUnknownRef,
+		},
+		{
+			name: "unknown base",
+			set: MessageSet{Set: map[string]StandardSchema{
+				"XYZ": {Category: "XYZ", Extends: "NOPE"},
+			}},
+			expected: ErrorUnknownBase,
+		},
+		{
+			name: "circular ref",
+			set: MessageSet{
+				Catalogue: map[string]DataField{
+					"A": {DataItem: "A", Type: StructuredField, Subfields: []DataField{{Ref: "B"}}},
+					"B": {DataItem: "B", Type: StructuredField, Subfields: []DataField{{Ref: "A"}}},
+				},
+				Set: map[string]StandardSchema{"XYZ": {Category: "XYZ"}},
+			},
+			expected: ErrorCircularRef,
+		},
+		{
+			name: "circular extends",
+			set: MessageSet{Set: map[string]StandardSchema{
+				"XYA": {Category: "XYA", Extends: "XYB"},
+				"XYB": {Category: "XYB", Extends: "XYA"},
+			}},
+			expected: ErrorCircularRef,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.set.Resolve(); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func Test_MessageSetFromJSON_Catalogue(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"catalogue.json": `{"Name": "ops", "Fields": [{"DataItem": "CALLSIGN", "Description": "Callsign", "Type": 0, "Target": "ARCID"}]}`,
+		"OFP.json":       `{"Name": "ops", "Category": "OFP", "Items": [{"FRN": 1, "Ref": "TITLE"}, {"FRN": 2, "Ref": "CALLSIGN", "Mendatory": true}, {"FRN": 3, "Ref": "EOBT"}]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set, err := MessageSetFromJSON(dir, "ops")
+	if err != nil {
+		t.Fatalf("MessageSetFromJSON failed: %v", err)
+	}
+	fp, err := NewParser([]MessageSet{*set}).Parse("-TITLE OFP -CALLSIGN DLH4AB -EOBT 1030")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fp["ARCID"] != "DLH4AB" || fp["EOBT"] != (Time{Hour: 10, Minute: 30}) {
+		t.Errorf("Unexpected flight plan: %v", fp)
+	}
+}
+
+func Test_MessageSetFromJSON_Extends(t *testing.T) {
+	set := LoadTestMessageSet(t)
+	schema := set.Set["BFD"]
+	base := StandardMessageSet().Set["BFD"]
+	if schema.Version != "0.1" || len(schema.Items) != len(base.Items) {
+		t.Errorf("Expected the BFD version 0.1 to hold %d fields, got %s %d", len(base.Items), schema.Version, len(schema.Items))
+	}
+	for _, name := range []string{"NBARC", "SEQPT", "ALTRNT1", "ALTRNT2"} {
+		if fieldNamed(name, schema.Items) == nil {
+			t.Errorf("Expected the custom BFD to inherit %s", name)
+		}
+	}
+	if adep := fieldNamed("ADEP", schema.Items); adep == nil || adep.Format != "" {
+		t.Errorf("Expected ADEP to be overridden without Format, got %+v", adep)
+	}
+
+	fp, err := NewParser([]MessageSet{set}).Parse("-TITLE BFD -REFDATA -SENDER -FAC EBBUZXZQ -RECVR -FAC EBSZZXZQ -SEQNUM 006 -ARCID DLH151 -ADEP EDDW -NBARC 2 -ALTRNT1 EDDF")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fp["ADEP"] != "EDDW" || fp["NBARC"] != "2" || fp["ALTRNT1"] != LocationIndicator("EDDF") {
+		t.Errorf("Unexpected flight plan: %v", fp)
+	}
+}
+
+func fieldNamed(name string, fields []DataField) *DataField {
+	return (&parseState{}).findField(name, fields)
+}
@@ -0,0 +1,223 @@
+package adexp
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Encoder writes ADEXP messages to an output stream
+type Encoder struct {
+	MessageSet []MessageSet
+	// Version selects the version of the schemas used, any version if empty
+	Version string
+	w       io.Writer
+}
+
+// NewEncoder creates a new Encoder writing to w with the given schema
+func NewEncoder(w io.Writer, schema []MessageSet) *Encoder {
+	return &Encoder{
+		MessageSet: schema,
+		w:          w,
+	}
+}
+
+// Encode writes the ADEXP representation of fp, using the schema matching its TITLE
+func (e *Encoder) Encode(fp map[string]interface{}) error {
+	title, ok := fp["TITLE"].(string)
+	if !ok || title == "" {
+		return fmt.Errorf("TITLE field not found in the flight plan")
+	}
+	schema, err := selectSchema(e.MessageSet, title, e.Version, "")
+	if err != nil {
+		return err
+	}
+	s, err := Marshal(fp, *schema)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(e.w, s)
+	return err
+}
+
+// Marshal returns the ADEXP text of fp. Fields are written in the FRN order of
+// the schema, one primary field per line; fields not defined in the schema are
+// left out, just as the parser skips them. Values of fields declaring a
+// Target are read from the Target path.
+func Marshal(fp map[string]interface{}, schema StandardSchema) (string, error) {
+	var b strings.Builder
+	fp = restoreTargets(schema.Items, fp, fp)
+
+	title, _ := fp["TITLE"].(string)
+	if title == "" {
+		title = schema.Category
+	}
+	b.WriteString("-TITLE " + title + "\n")
+
+	written := map[string]bool{"TITLE": true}
+	for _, field := range sortedFields(schema.Items) {
+		if written[field.DataItem] {
+			continue
+		}
+		value, ok := fp[field.DataItem]
+		if !ok {
+			continue
+		}
+		written[field.DataItem] = true
+		if err := encodeOccurrences(&b, field, value, "\n"); err != nil {
+			return "", err
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String(), nil
+}
+
+// encodeOccurrences writes every occurrence of a repeatable field, separated
+// by sep, or the single value of any other field
+func encodeOccurrences(b *strings.Builder, field DataField, value interface{}, sep string) error {
+	occurrences, ok := value.([]interface{})
+	if !field.Repeatable || field.Type == ListField || !ok {
+		return encodeField(b, field, value)
+	}
+	for i, occurrence := range occurrences {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		if err := encodeField(b, field, occurrence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeField writes a single field and its subfields
+func encodeField(b *strings.Builder, field DataField, value interface{}) error {
+	switch field.Type {
+	case Basicfield:
+		return encodeBasicField(b, field, value)
+	case StructuredField:
+		return encodeStructuredField(b, field, value)
+	case ListField:
+		return encodeListField(b, field, value)
+	default:
+		return fmt.Errorf("unknown field type for field '%s'", field.DataItem)
+	}
+}
+
+// encodeBasicField writes "-NAME value", or "-NAME" for an empty value
+func encodeBasicField(b *strings.Builder, field DataField, value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case nil:
+	case map[string]interface{}, []interface{}:
+		return fmt.Errorf("field '%s': expected a basic value, got %T", field.DataItem, value)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	b.WriteString("-" + field.DataItem)
+	if s != "" {
+		b.WriteString(" " + s)
+	}
+	return nil
+}
+
+// encodeStructuredField writes "-NAME" followed by its subfields on the same line
+func encodeStructuredField(b *strings.Builder, field DataField, value interface{}) error {
+	structuredData, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("field '%s': expected a structured value, got %T", field.DataItem, value)
+	}
+
+	b.WriteString("-" + field.DataItem)
+	for _, subfield := range sortedFields(field.Subfields) {
+		subValue, ok := structuredData[subfield.DataItem]
+		if !ok {
+			continue
+		}
+		b.WriteByte(' ')
+		if err := encodeOccurrences(b, subfield, subValue, " "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeListField writes a -BEGIN/-END block with one list item per line
+func encodeListField(b *strings.Builder, field DataField, value interface{}) error {
+	listData, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("field '%s': expected a list value, got %T", field.DataItem, value)
+	}
+
+	b.WriteString("-BEGIN " + field.DataItem + "\n")
+	for _, item := range listData {
+		if err := encodeListItem(b, field.Subfields, item); err != nil {
+			return fmt.Errorf("error encoding list field '%s': %w", field.DataItem, err)
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("-END " + field.DataItem)
+	return nil
+}
+
+// encodeListItem writes a single list item. Structured list items may be given
+// either keyed by their subfield (e.g. {"PT": {...}}) or in the flattened form
+// produced with FlattenListItems (e.g. {"PTID": ..., "TO": ...}).
+func encodeListItem(b *strings.Builder, subfields []DataField, item interface{}) error {
+	if len(subfields) == 1 && subfields[0].Type == Basicfield {
+		return encodeBasicField(b, subfields[0], item)
+	}
+
+	itemData, ok := item.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a structured list item, got %T", item)
+	}
+
+	written := false
+	for _, subfield := range sortedFields(subfields) {
+		subValue, ok := itemData[subfield.DataItem]
+		if !ok {
+			if subfield.Type != StructuredField || !containsSubfield(itemData, subfield.Subfields) {
+				continue
+			}
+			subValue = itemData
+		}
+		if written {
+			b.WriteByte(' ')
+		}
+		if err := encodeField(b, subfield, subValue); err != nil {
+			return err
+		}
+		written = true
+	}
+	if !written {
+		return fmt.Errorf("empty structured list item")
+	}
+	return nil
+}
+
+// containsSubfield reports whether data holds a value for any of the subfields
+func containsSubfield(data map[string]interface{}, subfields []DataField) bool {
+	for _, subfield := range subfields {
+		if _, ok := data[subfield.DataItem]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedFields returns a copy of fields ordered by FRN, keeping the schema
+// order for fields sharing the same FRN
+func sortedFields(fields []DataField) []DataField {
+	sorted := make([]DataField, len(fields))
+	copy(sorted, fields)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].FRN < sorted[j].FRN
+	})
+	return sorted
+}
@@ -0,0 +1,133 @@
+package adexp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Marshal(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	schema, err := findSchema(testSchema, "SAM")
+	if err != nil {
+		t.Fatalf("Failed to find schema: %v", err)
+	}
+
+	fp := map[string]interface{}{
+		"TITLE":  "SAM",
+		"ADEP":   "EGLL",
+		"ARCID":  "AMC101",
+		"TTO":    map[string]interface{}{"PTID": "GZO", "TO": "1438", "FL": "F060"},
+		"UNKNWN": "IGNORED",
+	}
+
+	s, err := Marshal(fp, *schema)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := "-TITLE SAM\n-ARCID AMC101\n-ADEP EGLL\n-TTO -PTID GZO -TO 1438 -FL F060\n"
+	if s != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, s)
+	}
+}
+
+func Test_Marshal_ListField(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	schema, err := findSchema(testSchema, "BFD")
+	if err != nil {
+		t.Fatalf("Failed to find schema: %v", err)
+	}
+
+	fp := map[string]interface{}{
+		"TITLE": "BFD",
+		"RTEPTS": []interface{}{
+			map[string]interface{}{"PTID": "WOODY", "TO": "1235"},
+			map[string]interface{}{"PT": map[string]interface{}{"PTID": "CIV", "FL": "F330"}},
+		},
+		"EQCST": []interface{}{"W/EQ", "Y/NO"},
+	}
+
+	s, err := Marshal(fp, *schema)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, expected := range []string{
+		"-BEGIN RTEPTS\n-PT -PTID WOODY -TO 1235\n-PT -PTID CIV -FL F330\n-END RTEPTS\n",
+		"-BEGIN EQCST\n-EQPT W/EQ\n-EQPT Y/NO\n-END EQCST\n",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Expected output to contain:\n%s\ngot:\n%s", expected, s)
+		}
+	}
+}
+
+func Test_Marshal_Errors(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	schema, err := findSchema(testSchema, "BFD")
+	if err != nil {
+		t.Fatalf("Failed to find schema: %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		fp   map[string]interface{}
+	}{
+		{name: "basic field with map", fp: map[string]interface{}{"ARCID": map[string]interface{}{}}},
+		{name: "structured field with string", fp: map[string]interface{}{"REFDATA": "X"}},
+		{name: "list field with string", fp: map[string]interface{}{"RTEPTS": "X"}},
+		{name: "empty list item", fp: map[string]interface{}{"RTEPTS": []interface{}{map[string]interface{}{}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Marshal(tc.fp, *schema); err == nil {
+				t.Errorf("Expected an error, got nil")
+			}
+		})
+	}
+}
+
+func Test_Encoder_RoundTrip(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	parser := NewParser(testSchema)
+
+	for _, filename := range []string{"BFD.txt", "CFD.txt", "TFD.txt", "SAM.txt", "SRM.txt", "SLC.txt", "FLS.txt", "DES.txt", "ACT.txt", "OFP.txt"} {
+		t.Run(filename, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("../test/fpl/adexp", filename))
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+			expected, err := parser.Parse(string(content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := NewEncoder(&buf, testSchema).Encode(expected); err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			result, err := parser.Parse(buf.String())
+			if err != nil {
+				t.Fatalf("Parse of encoded message failed: %v\n%s", err, buf.String())
+			}
+			if !reflect.DeepEqual(expected, result) {
+				t.Errorf("Round trip mismatch:\nexpected %v\ngot      %v", expected, result)
+			}
+		})
+	}
+}
+
+func Test_Encoder_NoSchema(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, []MessageSet{LoadTestMessageSet(t)})
+	if err := enc.Encode(map[string]interface{}{"TITLE": "ABC"}); err == nil || err.Error() != "no matching schema found for title: ABC" {
+		t.Errorf("Expected error 'no matching schema found for title: ABC', got: %v", err)
+	}
+	if err := enc.Encode(map[string]interface{}{}); err == nil {
+		t.Errorf("Expected an error for a missing TITLE, got nil")
+	}
+}
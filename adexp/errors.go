@@ -0,0 +1,97 @@
+package adexp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode classifies a ParseError
+type ErrorCode string
+
+const (
+	CodeInvalidCharacter ErrorCode = "INVALID_CHARACTER"
+	CodeNoTitle          ErrorCode = "NO_TITLE"
+	CodeNoSchema         ErrorCode = "NO_SCHEMA"
+	CodeInvalidField     ErrorCode = "INVALID_FIELD"
+	CodeUnknownField     ErrorCode = "UNKNOWN_FIELD"
+	CodeDuplicateField   ErrorCode = "DUPLICATE_FIELD"
+	CodeEndMismatch      ErrorCode = "END_MISMATCH"
+	CodeInvalidValue     ErrorCode = "INVALID_VALUE"
+)
+
+var ErrorNoTitle = errors.New("TITLE field not found in the message")
+
+// ParseError reports where parsing an ADEXP message failed
+type ParseError struct {
+	// Offset is the byte offset in the message as passed to Parse. Line and
+	// Column are 1-based; all three are 0 if the error has no position.
+	Offset int
+	Line   int
+	Column int
+	// Path is the dotted path of the field being parsed, e.g. REFDATA.SENDER
+	Path string
+	Code ErrorCode
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d, column %d: ", e.Line, e.Column)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, "field %s: ", e.Path)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// errorAt returns a ParseError at pos of the prepared message for the field currently parsed
+func (p *parseState) errorAt(pos int, code ErrorCode, err error) *ParseError {
+	offset := pos + p.offset
+	before := p.original[:min(offset, len(p.original))]
+	return &ParseError{
+		Offset: offset,
+		Line:   strings.Count(before, "\n") + 1,
+		Column: len(before) - strings.LastIndex(before, "\n"),
+		Path:   strings.Join(p.path, "."),
+		Code:   code,
+		Err:    err,
+	}
+}
+
+// warn records a warning at pos of the prepared message
+func (p *parseState) warn(pos int, code ErrorCode, err error) {
+	p.warnings = append(p.warnings, p.errorAt(pos, code, err))
+}
+
+// setField stores a field value. Repeatable fields collect every occurrence
+// in a list; for other fields the last value is kept with a warning.
+func (p *parseState) setField(data map[string]interface{}, pos int, field *DataField, value interface{}) {
+	key := field.DataItem
+	if field.Repeatable {
+		list, _ := data[key].([]interface{})
+		data[key] = append(list, value)
+		return
+	}
+	if _, ok := data[key]; ok {
+		p.pushField(key)
+		p.warn(pos, CodeDuplicateField, fmt.Errorf("duplicate field '%s'", key))
+		p.popField()
+	}
+	data[key] = value
+}
+
+// pushField and popField track the path of the field currently parsed
+func (p *parseState) pushField(name string) {
+	p.path = append(p.path, name)
+}
+
+func (p *parseState) popField() {
+	p.path = p.path[:len(p.path)-1]
+}
@@ -1,11 +1,10 @@
 package adexp
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"strings"
+	"path/filepath"
 )
 
 const (
@@ -14,65 +13,102 @@ const (
 	StructuredField
 )
 
+// StandardSchema describes the fields of the messages of a title. YAML
+// schema files use the same keys as JSON ones.
 type StandardSchema struct {
-	Name     string
-	Category string
-	Version  string
-	Items    []DataField
+	Name     string `yaml:"Name"`
+	Category string `yaml:"Category"`
+	Version  string `yaml:"Version"`
+	// Extends names the schema whose items this schema inherits. Items
+	// with the DataItem of an inherited item replace it, the others are
+	// added. See MessageSet.Resolve.
+	Extends string      `yaml:"Extends"`
+	Items   []DataField `yaml:"Items"`
 }
 
 // DataField describes FRN(Field Reference Number)
 type DataField struct {
-	FRN         uint8
-	DataItem    string
-	Description string
-	Type        uint8
-	Mendatory   bool
-	Target      string
-	Subfields   []DataField
+	FRN      uint8  `yaml:"FRN"`
+	DataItem string `yaml:"DataItem"`
+	// Ref names the catalogue field this field is defined by. The field
+	// only sets what differs, e.g. its FRN and Mendatory.
+	Ref         string `yaml:"Ref"`
+	Description string `yaml:"Description"`
+	Type        uint8  `yaml:"Type"`
+	Mendatory   bool   `yaml:"Mendatory"`
+	// Repeatable fields may occur several times outside a list and are
+	// returned as a list of all occurrences in message order
+	Repeatable bool `yaml:"Repeatable"`
+	// Format declares the value format of a basic field, whose value is
+	// then returned as the matching type, e.g. Time for FormatTime
+	Format Format `yaml:"Format"`
+	// Target is the dotted path the value is returned at instead of
+	// DataItem, e.g. ARCID for a custom CALLSIGN field. Paths start at the
+	// message, or at the item for subfields of list items.
+	Target    string      `yaml:"Target"`
+	Subfields []DataField `yaml:"Subfields"`
 }
 
 type MessageSet struct {
 	Name string
 	Set  map[string]StandardSchema
+	// Catalogue holds the fields schemas reference by name, loaded from
+	// schema files with Fields instead of a Category
+	Catalogue map[string]DataField
 }
 
 var ErrorFieldNotPresent = fmt.Errorf("field not present")
 var ErrorMendatory = fmt.Errorf("mendatory field not present")
 
+// MessageSetFromJSON loads the message set n from the JSON schema files in
+// directory p. See MessageSetFromJSONWithWarnings for the checks applied.
 func MessageSetFromJSON(p string, n string) (*MessageSet, error) {
-	var set MessageSet
-	set.Set = make(map[string]StandardSchema, 0)
+	set, _, err := MessageSetFromJSONWithWarnings(p, n)
+	return set, err
+}
+
+// MessageSetFromJSONWithWarnings loads the message set n like
+// MessageSetFromJSON and also returns the unknown keys, duplicate DataItems
+// and FRN inconsistencies found as *SchemaError. Loading fails with a
+// *SchemaError for each duplicate category or catalogue field, invalid Type
+// and empty Subfields of a list or structured field.
+// Subdirectories and files without the .json extension are ignored, see
+// MessageSetFromFS to load them.
+func MessageSetFromJSONWithWarnings(p string, n string) (*MessageSet, []error, error) {
 	files, err := os.ReadDir(p)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	l := newLoader(n)
 	for _, file := range files {
-		var schema = StandardSchema{}
-		// Check if it's a regular file (not a directory)
-		if file.Type().IsRegular() {
-			// Get the full path of the file
-			if strings.Split(file.Name(), ".")[1] != "json" {
-				continue
-			}
-			filePath := p + "/" + file.Name()
-
-			// Read the file's contents
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(content, &schema)
-			if err != nil {
-				return nil, err
-			}
-			set.Set[schema.Category] = schema
+		if !file.Type().IsRegular() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(p, file.Name()))
+		if err != nil {
+			return nil, nil, err
 		}
+		l.addJSON(file.Name(), content)
 	}
+	return l.finish(StandardMessageSet())
+}
 
-	if len(set.Set) == 0 {
-		return nil, errors.New("length of set is 0")
-	}
+// MessageSetFromFS loads the message set n from the JSON (.json) and YAML
+// (.yaml, .yml) schema files of fsys and its subdirectories, e.g. an embed.FS
+// or os.DirFS. Files are named by their path in fsys in errors. See
+// MessageSetFromJSONWithWarnings for the checks applied.
+func MessageSetFromFS(fsys fs.FS, n string) (*MessageSet, error) {
+	set, _, err := MessageSetFromFSWithWarnings(fsys, n)
+	return set, err
+}
 
-	return &set, nil
+// MessageSetFromFSWithWarnings loads the message set n like
+// MessageSetFromFS and also returns the warnings found, see
+// MessageSetFromJSONWithWarnings
+func MessageSetFromFSWithWarnings(fsys fs.FS, n string) (*MessageSet, []error, error) {
+	l := newLoader(n)
+	if err := l.addFS(fsys); err != nil {
+		return nil, nil, err
+	}
+	return l.finish(StandardMessageSet())
 }
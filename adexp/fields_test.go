@@ -1,11 +1,20 @@
 package adexp
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
 
 func Test_MessageSetFromJSON(t *testing.T) {
-	_, err := MessageSetFromJSON("../test/schema", "test")
+	set, err := MessageSetFromJSON("../test/schema", "test")
 	if err != nil {
-		t.Errorf("error loading json: %v\n", err)
+		t.Fatalf("error loading json: %v\n", err)
+	}
+	if set.Name != "test" {
+		t.Errorf("Expected the set to be named test, got %s", set.Name)
 	}
 }
 
@@ -20,3 +29,230 @@ func Test_MessageSetFromJSON_ERROR(t *testing.T) {
 		t.Errorf("Expected error loading json: %v\n", err.Error())
 	}
 }
+
+func Test_MessageSetFromJSON_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected error
+		message  string
+	}{
+		{
+			name:    "invalid value",
+			files:   map[string]string{"XYZ.json": `{"Category": "XYZ", "Items": [{"FRN": "1", "DataItem": "TITLE"}]}`},
+			message: "XYZ.json: json: cannot unmarshal string into Go struct field schemaFile.Items.0.FRN of type uint8",
+		},
+		{
+			name: "duplicate category",
+			files: map[string]string{
+				"XYZ.json":  `{"Category": "XYZ", "Items": [{"FRN": 1, "DataItem": "TITLE"}]}`,
+				"XYZ2.json": `{"Category": "XYZ", "Items": [{"FRN": 1, "DataItem": "TITLE"}]}`,
+			},
+			expected: ErrorDuplicateCategory,
+			message:  "XYZ2.json: Category: duplicate category 'XYZ', also defined in XYZ.json",
+		},
+		{
+			name:     "invalid type",
+			files:    map[string]string{"XYZ.json": `{"Category": "XYZ", "Items": [{"FRN": 1, "DataItem": "TITLE", "Type": 3}]}`},
+			expected: ErrorInvalidType,
+			message:  "XYZ.json: Items[0].Type: invalid Type 3, expected 0 (basic), 1 (list) or 2 (structured)",
+		},
+		{
+			name:     "empty subfields",
+			files:    map[string]string{"XYZ.json": `{"Category": "XYZ", "Items": [{"FRN": 1, "DataItem": "RTEPTS", "Type": 1, "Subfields": [{"FRN": 1, "DataItem": "PT", "Type": 2}]}]}`},
+			expected: ErrorEmptySubfields,
+			message:  "XYZ.json: Items[0].Subfields[0].Subfields: empty Subfields for PT",
+		},
+		{
+			name:     "unknown ref",
+			files:    map[string]string{"XYZ.json": `{"Category": "XYZ", "Items": [{"FRN": 1, "Ref": "NOPE"}]}`},
+			expected: ErrorUnknownRef,
+			message:  "XYZ.json: schema XYZ: unknown catalogue field 'NOPE'",
+		},
+		{
+			name:     "no category",
+			files:    map[string]string{"XYZ.json": `{"Name": "custom"}`},
+			expected: ErrorNoCategory,
+			message:  "XYZ.json: schema has no Category",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := MessageSetFromJSON(dir, "test")
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("Expected a SchemaError, got %v", err)
+			}
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func Test_MessageSetFromJSONWithWarnings(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"Category": "XYZ", "Items": [{"FRN": 1, "DataItem": "TITLE", "Mandatory": true}, {"FRN": 3, "DataItem": "ARCID"}, {"FRN": 2, "DataItem": "ADEP"}, {"DataItem": "ADES"}, {"FRN": 4, "DataItem": "ARCID"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "XYZ.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, warnings, err := MessageSetFromJSONWithWarnings(dir, "test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(set.Set["XYZ"].Items) != 5 {
+		t.Errorf("Expected the schema to be loaded despite the warnings")
+	}
+	expected := []struct {
+		err     error
+		message string
+	}{
+		{ErrorUnknownKey, `XYZ.json: unknown key: json: unknown field "Mandatory"`},
+		{ErrorInconsistentFRN, "XYZ.json: Items[2].FRN: inconsistent FRN: 2 does not follow 3"},
+		{ErrorInconsistentFRN, "XYZ.json: Items[3].FRN: inconsistent FRN: missing"},
+		{ErrorDuplicateDataItem, "XYZ.json: Items[4].DataItem: duplicate DataItem 'ARCID', also at Items[1]"},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, warning := range warnings {
+		if !errors.Is(warning, expected[i].err) || warning.Error() != expected[i].message {
+			t.Errorf("Expected warning %q, got %q", expected[i].message, warning.Error())
+		}
+	}
+}
+
+func Test_MessageSetFromJSON_OtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"XYZ.json":      `{"Category": "XYZ", "Items": [{"FRN": 1, "DataItem": "TITLE"}]}`,
+		"README":        "not a schema",
+		"XYZ.json.orig": "not a schema",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.json"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := MessageSetFromJSON(dir, "test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(set.Set) != 1 {
+		t.Errorf("Expected only XYZ.json to be loaded, got %v", set.Set)
+	}
+}
+
+func Test_MessageSetFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"catalogue.yaml": {Data: []byte("Fields:\n  - DataItem: CALLSIGN\n    Type: 0\n    Target: ARCID\n")},
+		"ofp/OFP.yml": {Data: []byte(`Category: OFP
+Items:
+  - FRN: 1
+    Ref: TITLE
+  - FRN: 2
+    Ref: CALLSIGN
+    Mendatory: true
+  - FRN: 3
+    Ref: EOBT
+`)},
+		"ofp/XSM.json":  {Data: []byte(`{"Category": "XSM", "Extends": "SAM", "Items": [{"FRN": 21, "DataItem": "EXTRA", "Type": 0}]}`)},
+		"ofp/README.md": {Data: []byte("not a schema")},
+	}
+
+	set, err := MessageSetFromFS(fsys, "ops")
+	if err != nil {
+		t.Fatalf("MessageSetFromFS failed: %v", err)
+	}
+	if set.Name != "ops" || len(set.Set) != 2 {
+		t.Errorf("Expected OFP and XSM in the set ops, got %s %v", set.Name, set.Set)
+	}
+	fp, err := NewParser([]MessageSet{*set}).Parse("-TITLE OFP -CALLSIGN DLH4AB -EOBT 1030")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fp["ARCID"] != "DLH4AB" || fp["EOBT"] != (Time{Hour: 10, Minute: 30}) {
+		t.Errorf("Unexpected flight plan: %v", fp)
+	}
+}
+
+func Test_MessageSetFromFS_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		expected error
+		message  string
+	}{
+		{
+			name:    "invalid value",
+			fsys:    fstest.MapFS{"sub/XYZ.yaml": {Data: []byte("Category: XYZ\nItems:\n  - FRN: one\n    DataItem: TITLE\n")}},
+			message: "sub/XYZ.yaml: yaml: unmarshal errors:\n  line 3: cannot unmarshal !!str `one` into uint8",
+		},
+		{
+			name: "duplicate category",
+			fsys: fstest.MapFS{
+				"a/XYZ.json": {Data: []byte(`{"Category": "XYZ", "Items": [{"FRN": 1, "DataItem": "TITLE"}]}`)},
+				"b/XYZ.yaml": {Data: []byte("Category: XYZ\nItems:\n  - FRN: 1\n    DataItem: TITLE\n")},
+			},
+			expected: ErrorDuplicateCategory,
+			message:  "b/XYZ.yaml: Category: duplicate category 'XYZ', also defined in a/XYZ.json",
+		},
+		{
+			name:     "empty file",
+			fsys:     fstest.MapFS{"XYZ.yml": {Data: []byte("")}},
+			expected: ErrorNoCategory,
+			message:  "XYZ.yml: schema has no Category",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MessageSetFromFS(tt.fsys, "test")
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("Expected a SchemaError, got %v", err)
+			}
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+
+	if _, err := MessageSetFromFS(fstest.MapFS{}, "test"); err == nil || err.Error() != "length of set is 0" {
+		t.Errorf("Expected an empty set to fail, got %v", err)
+	}
+}
+
+func Test_MessageSetFromFSWithWarnings(t *testing.T) {
+	fsys := fstest.MapFS{"sub/XYZ.yaml": {Data: []byte("Category: XYZ\nItems:\n  - FRN: 1\n    DataItem: TITLE\n    Mandatory: true\n")}}
+
+	set, warnings, err := MessageSetFromFSWithWarnings(fsys, "test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(set.Set["XYZ"].Items) != 1 {
+		t.Errorf("Expected the schema to be loaded despite the unknown key")
+	}
+	expected := "sub/XYZ.yaml: unknown key: yaml: unmarshal errors:\n  line 5: field Mandatory not found in type adexp.DataField"
+	if len(warnings) != 1 || !errors.Is(warnings[0], ErrorUnknownKey) || warnings[0].Error() != expected {
+		t.Errorf("Expected warning %q, got %v", expected, warnings)
+	}
+}
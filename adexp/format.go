@@ -0,0 +1,205 @@
+package adexp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Format is the value format a schema declares for a basic field. Fields
+// without a format are returned as strings.
+type Format string
+
+const (
+	FormatTime        Format = "TIME"     // HHMM, e.g. 0945
+	FormatDate        Format = "DATE"     // YYMMDD, e.g. 240228
+	FormatFlightLevel Format = "FL"       // F330, A045, S1130 or M0840
+	FormatSpeed       Format = "SPEED"    // N0480, K0650 or M082
+	FormatSSRCode     Format = "SSRCODE"  // A2157
+	FormatLocation    Format = "LOCATION" // ICAO location indicator, e.g. EDDF
+	FormatLatLong     Format = "LATLONG"  // 4530N00630E or 453010N0063020E
+)
+
+var ErrorInvalidFormat = errors.New("value does not match the declared format")
+
+var (
+	timePattern        = regexp.MustCompile(`^([01][0-9]|2[0-3])([0-5][0-9])$`)
+	flightLevelPattern = regexp.MustCompile(`^(?:([FA])([0-9]{3})|([SM])([0-9]{4}))$`)
+	speedPattern       = regexp.MustCompile(`^(?:([NK])([0-9]{4})|(M)([0-9]{3}))$`)
+	ssrCodePattern     = regexp.MustCompile(`^(A)([0-7]{4})$`)
+	locationPattern    = regexp.MustCompile(`^[A-Z]{4}$`)
+	latLongPattern     = regexp.MustCompile(`^([0-9]{2})([0-5][0-9])([0-5][0-9])?([NS])([0-9]{3})([0-5][0-9])([0-5][0-9])?([EW])$`)
+)
+
+// Time is a time of day in HHMM format
+type Time struct {
+	Hour   int
+	Minute int
+}
+
+func (t Time) String() string {
+	return fmt.Sprintf("%02d%02d", t.Hour, t.Minute)
+}
+
+// Date is a date in YYMMDD format
+type Date struct {
+	time.Time
+}
+
+func (d Date) String() string {
+	return d.Format("060102")
+}
+
+// FlightLevel is a level in flight levels (F), altitude in hundreds of feet
+// (A), standard metric level (S) or altitude in tens of metres (M)
+type FlightLevel struct {
+	Unit  string
+	Value int
+}
+
+func (l FlightLevel) String() string {
+	if l.Unit == "S" || l.Unit == "M" {
+		return fmt.Sprintf("%s%04d", l.Unit, l.Value)
+	}
+	return fmt.Sprintf("%s%03d", l.Unit, l.Value)
+}
+
+// Speed is a speed in knots (N), kilometres per hour (K) or hundredths of
+// Mach (M)
+type Speed struct {
+	Unit  string
+	Value int
+}
+
+func (s Speed) String() string {
+	if s.Unit == "M" {
+		return fmt.Sprintf("M%03d", s.Value)
+	}
+	return fmt.Sprintf("%s%04d", s.Unit, s.Value)
+}
+
+// SSRCode is an SSR mode and its octal code
+type SSRCode struct {
+	Mode string
+	Code string
+}
+
+func (c SSRCode) String() string {
+	return c.Mode + c.Code
+}
+
+// LocationIndicator is a four letter ICAO location indicator
+type LocationIndicator string
+
+func (l LocationIndicator) String() string {
+	return string(l)
+}
+
+// LatLong is a geographical position in decimal degrees, negative south and west
+type LatLong struct {
+	Lat float64
+	Lon float64
+}
+
+// String returns the position in degrees and minutes, with seconds only if
+// they are not zero
+func (l LatLong) String() string {
+	latD, latM, latS := sexagesimal(l.Lat)
+	lonD, lonM, lonS := sexagesimal(l.Lon)
+	ns, ew := "N", "E"
+	if l.Lat < 0 {
+		ns = "S"
+	}
+	if l.Lon < 0 {
+		ew = "W"
+	}
+	if latS == 0 && lonS == 0 {
+		return fmt.Sprintf("%02d%02d%s%03d%02d%s", latD, latM, ns, lonD, lonM, ew)
+	}
+	return fmt.Sprintf("%02d%02d%02d%s%03d%02d%02d%s", latD, latM, latS, ns, lonD, lonM, lonS, ew)
+}
+
+// sexagesimal splits decimal degrees into degrees, minutes and seconds
+func sexagesimal(v float64) (int, int, int) {
+	seconds := int(math.Round(math.Abs(v) * 3600))
+	return seconds / 3600, seconds / 60 % 60, seconds % 60
+}
+
+// ParseValue converts the text of a basic field to the typed value of format
+func ParseValue(format Format, s string) (interface{}, error) {
+	invalid := fmt.Errorf("'%s' is not a valid %s: %w", s, format, ErrorInvalidFormat)
+
+	switch format {
+	case "":
+		return s, nil
+	case FormatTime:
+		m := timePattern.FindStringSubmatch(s)
+		if m == nil {
+			return nil, invalid
+		}
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		return Time{Hour: hour, Minute: minute}, nil
+	case FormatDate:
+		t, err := time.Parse("060102", s)
+		if err != nil {
+			return nil, invalid
+		}
+		return Date{t}, nil
+	case FormatFlightLevel:
+		m := flightLevelPattern.FindStringSubmatch(s)
+		if m == nil {
+			return nil, invalid
+		}
+		unit, digits := m[1]+m[3], m[2]+m[4]
+		value, _ := strconv.Atoi(digits)
+		return FlightLevel{Unit: unit, Value: value}, nil
+	case FormatSpeed:
+		m := speedPattern.FindStringSubmatch(s)
+		if m == nil {
+			return nil, invalid
+		}
+		unit, digits := m[1]+m[3], m[2]+m[4]
+		value, _ := strconv.Atoi(digits)
+		return Speed{Unit: unit, Value: value}, nil
+	case FormatSSRCode:
+		m := ssrCodePattern.FindStringSubmatch(s)
+		if m == nil {
+			return nil, invalid
+		}
+		return SSRCode{Mode: m[1], Code: m[2]}, nil
+	case FormatLocation:
+		if !locationPattern.MatchString(s) {
+			return nil, invalid
+		}
+		return LocationIndicator(s), nil
+	case FormatLatLong:
+		m := latLongPattern.FindStringSubmatch(s)
+		if m == nil {
+			return nil, invalid
+		}
+		lat := degrees(m[1], m[2], m[3])
+		lon := degrees(m[5], m[6], m[7])
+		if lat > 90 || lon > 180 {
+			return nil, invalid
+		}
+		if m[4] == "S" {
+			lat = -lat
+		}
+		if m[8] == "W" {
+			lon = -lon
+		}
+		return LatLong{Lat: lat, Lon: lon}, nil
+	}
+	return nil, fmt.Errorf("unknown format '%s'", format)
+}
+
+func degrees(deg, min, sec string) float64 {
+	d, _ := strconv.Atoi(deg)
+	m, _ := strconv.Atoi(min)
+	s, _ := strconv.Atoi(sec)
+	return float64(d) + float64(m)/60 + float64(s)/3600
+}
@@ -0,0 +1,140 @@
+package adexp
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_ParseValue(t *testing.T) {
+	tests := []struct {
+		format   Format
+		input    string
+		expected interface{}
+	}{
+		{"", "DLH151", "DLH151"},
+		{FormatTime, "0945", Time{Hour: 9, Minute: 45}},
+		{FormatTime, "2359", Time{Hour: 23, Minute: 59}},
+		{FormatDate, "240229", Date{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}},
+		{FormatFlightLevel, "F330", FlightLevel{Unit: "F", Value: 330}},
+		{FormatFlightLevel, "A045", FlightLevel{Unit: "A", Value: 45}},
+		{FormatFlightLevel, "S1130", FlightLevel{Unit: "S", Value: 1130}},
+		{FormatFlightLevel, "M0840", FlightLevel{Unit: "M", Value: 840}},
+		{FormatSpeed, "N0480", Speed{Unit: "N", Value: 480}},
+		{FormatSpeed, "K0650", Speed{Unit: "K", Value: 650}},
+		{FormatSpeed, "M082", Speed{Unit: "M", Value: 82}},
+		{FormatSSRCode, "A2157", SSRCode{Mode: "A", Code: "2157"}},
+		{FormatLocation, "EDDF", LocationIndicator("EDDF")},
+		{FormatLatLong, "4530N00630E", LatLong{Lat: 45.5, Lon: 6.5}},
+		{FormatLatLong, "4530S00630W", LatLong{Lat: -45.5, Lon: -6.5}},
+		{FormatLatLong, "453036N0063036E", LatLong{Lat: 45.51, Lon: 6.51}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format)+" "+tt.input, func(t *testing.T) {
+			value, err := ParseValue(tt.format, tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("Expected %#v, got %#v", tt.expected, value)
+			}
+			if s, ok := value.(interface{ String() string }); ok && s.String() != tt.input {
+				t.Errorf("Expected String() to return %s, got %s", tt.input, s.String())
+			}
+		})
+	}
+}
+
+func Test_ParseValue_Invalid(t *testing.T) {
+	tests := []struct {
+		format Format
+		input  string
+	}{
+		{FormatTime, "2400"},
+		{FormatTime, "945"},
+		{FormatDate, "240230"},
+		{FormatFlightLevel, "FL330"},
+		{FormatFlightLevel, "S330"},
+		{FormatSpeed, "N480"},
+		{FormatSpeed, "M0820"},
+		{FormatSSRCode, "A2158"},
+		{FormatLocation, "EDD"},
+		{FormatLatLong, "4560N00630E"},
+		{FormatLatLong, "9130N00630E"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format)+" "+tt.input, func(t *testing.T) {
+			_, err := ParseValue(tt.format, tt.input)
+			if !errors.Is(err, ErrorInvalidFormat) {
+				t.Errorf("Expected ErrorInvalidFormat, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_Parse_InvalidValue(t *testing.T) {
+	set := *StandardMessageSet()
+	message := "-TITLE SAM -ARCID AMC101 -EOBT 0960 -TTO -PTID GZO -FL 060"
+
+	_, err := NewParser([]MessageSet{set}).Parse(message)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got %v", err)
+	}
+	if parseErr.Code != CodeInvalidValue || parseErr.Path != "EOBT" || parseErr.Column != 32 {
+		t.Errorf("Unexpected error: %+v", parseErr)
+	}
+	if !errors.Is(err, ErrorInvalidFormat) {
+		t.Errorf("Expected error to wrap ErrorInvalidFormat")
+	}
+
+	fp, warnings, err := NewParserWithOpts([]MessageSet{set}, ParserOpts{Mode: ModeLenient}).ParseWithWarnings(message)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+	if !errors.As(warnings[1], &parseErr) || parseErr.Path != "TTO.FL" {
+		t.Errorf("Expected a warning for TTO.FL, got %v", warnings[1])
+	}
+	if fp["EOBT"] != "0960" {
+		t.Errorf("Expected EOBT to be kept as '0960', got %#v", fp["EOBT"])
+	}
+	if tto := fp["TTO"].(map[string]interface{}); tto["FL"] != "060" {
+		t.Errorf("Expected TTO.FL to be kept as '060', got %#v", tto["FL"])
+	}
+}
+
+func Test_Parse_Formats(t *testing.T) {
+	content, err := os.ReadFile("../test/fpl/adexp/SAM.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	fp, err := NewParser([]MessageSet{*StandardMessageSet()}).Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"ARCID":    "AMC101",
+		"ADEP":     LocationIndicator("EGLL"),
+		"ADES":     LocationIndicator("LMML"),
+		"EOBD":     Date{time.Date(2016, 2, 24, 0, 0, 0, 0, time.UTC)},
+		"EOBT":     Time{Hour: 9, Minute: 45},
+		"CTOT":     Time{Hour: 12},
+		"TAXITIME": Time{Minute: 10},
+	}
+	for key, value := range expected {
+		if fp[key] != value {
+			t.Errorf("Expected %s to be %#v, got %#v", key, value, fp[key])
+		}
+	}
+	tto, _ := fp["TTO"].(map[string]interface{})
+	if tto["TO"] != (Time{Hour: 14, Minute: 38}) || tto["FL"] != (FlightLevel{Unit: "F", Value: 60}) {
+		t.Errorf("Expected typed TTO.TO and TTO.FL, got %v", fp["TTO"])
+	}
+}
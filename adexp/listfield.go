@@ -1,14 +1,15 @@
 package adexp
 
 import (
+	"errors"
 	"fmt"
-	"log"
 )
 
 // parseListField parses a list field and returns its key, value (as a slice), and any error
-func (p *Parser) parseListField(field DataField) (string, []interface{}, error) {
+func (p *parseState) parseListField(field DataField) (string, []interface{}, error) {
 	listData := make([]interface{}, 0)
-	log.Printf("Parsing list field: %s", field.DataItem)
+	p.pushField(field.DataItem)
+	defer p.popField()
 
 	for {
 		if p.checkForEndMarker(field.DataItem) {
@@ -16,6 +17,10 @@ func (p *Parser) parseListField(field DataField) (string, []interface{}, error)
 		}
 
 		item, err := p.parseListItem(field.Subfields)
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			return "", nil, err
+		}
 		if err != nil {
 			break
 		}
@@ -27,12 +32,11 @@ func (p *Parser) parseListField(field DataField) (string, []interface{}, error)
 		listData = append(listData, item)
 	}
 
-	log.Printf("Finished parsing list field: %s, found %d items", field.DataItem, len(listData))
 	return field.DataItem, listData, nil
 }
 
 // parseListItem parses a single item in a list field
-func (p *Parser) parseListItem(subfields []DataField) (interface{}, error) {
+func (p *parseState) parseListItem(subfields []DataField) (interface{}, error) {
 	if len(subfields) == 1 && subfields[0].Type == Basicfield {
 		// Handle simple list (e.g., EQCST)
 		return p.parseSimpleListItem(subfields[0])
@@ -43,22 +47,27 @@ func (p *Parser) parseListItem(subfields []DataField) (interface{}, error) {
 }
 
 // parseSimpleListItem parses a single item in a simple list field
-func (p *Parser) parseSimpleListItem(subfield DataField) (string, error) {
+func (p *parseState) parseSimpleListItem(subfield DataField) (interface{}, error) {
 	subFieldName, subFieldValue, err := p.parseSubField([]DataField{subfield})
 	if err != nil {
-		return "", fmt.Errorf("error parsing simple list item: %w", err)
+		return nil, fmt.Errorf("error parsing simple list item: %w", err)
 	}
 	if subFieldName == "" {
-		return "", fmt.Errorf("unexpected end of simple list item")
+		return nil, fmt.Errorf("unexpected end of simple list item")
 	}
-	return subFieldValue.(string), nil
+	return subFieldValue, nil
 }
 
-// parseStructuredListItem parses a single item in a structured list field
-func (p *Parser) parseStructuredListItem(subfields []DataField) (map[string]interface{}, error) {
+// parseStructuredListItem parses a single item in a structured list field.
+// Subfields are kept under their name, e.g. {"PT": {"PTID": ...}}, unless
+// FlattenListItems is set, in which case structured subfields are merged into
+// the item, e.g. {"PTID": ...}.
+func (p *parseState) parseStructuredListItem(subfields []DataField) (map[string]interface{}, error) {
 	item := make(map[string]interface{})
+	seen := make(map[string]bool)
 
 	for {
+		subFieldStart := p.currentPos
 		subFieldName, subFieldValue, err := p.parseSubField(subfields)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing structured list item: %w", err)
@@ -66,27 +75,26 @@ func (p *Parser) parseStructuredListItem(subfields []DataField) (map[string]inte
 		if subFieldName == "" {
 			break // End of current item or start of next item
 		}
+		seen[subFieldName] = true
 
 		// Check if the subfield is defined in the schema
 		if subField := p.findField(subFieldName, subfields); subField != nil {
-			// If the subfield value is a map, flatten it
-			if subValue, ok := subFieldValue.(map[string]interface{}); ok {
-
+			subValue, isMap := subFieldValue.(map[string]interface{})
+			if p.Opts.FlattenListItems && isMap {
 				for k, v := range subValue {
 					item[k] = v
 				}
 			} else {
-				panic(subFieldName)
-				item[subFieldName] = subFieldValue
+				p.setField(item, subFieldStart, subField, subFieldValue)
 			}
 		} else {
 			// Skip this field as it's not in the subfield definition
 			p.skipUnexpectedField()
-			log.Printf("Skipping undefined subfield: %s", subFieldName)
+			p.warn(p.currentPos, CodeUnknownField, fmt.Errorf("unknown subfield '%s'", subFieldName))
 		}
 
 		// Check if we've reached the start of a new item
-		if p.isStartOfNewItem(subfields) {
+		if p.isStartOfNewItem(subfields, seen) {
 			break
 		}
 	}
@@ -98,8 +106,10 @@ func (p *Parser) parseStructuredListItem(subfields []DataField) (map[string]inte
 	return item, nil
 }
 
-// isStartOfNewItem checks if the current position is the start of a new item in the list
-func (p *Parser) isStartOfNewItem(subfields []DataField) bool {
+// isStartOfNewItem checks if the current position is the start of a new item
+// in the list, that is a subfield the current item already holds. Repeatable
+// subfields may occur several times within one item.
+func (p *parseState) isStartOfNewItem(subfields []DataField, seen map[string]bool) bool {
 	originalPos := p.currentPos
 	p.buffer.Reset()
 
@@ -120,7 +130,7 @@ func (p *Parser) isStartOfNewItem(subfields []DataField) bool {
 
 		// Check if this field name is in the subfields list
 		for _, subfield := range subfields {
-			if subfield.DataItem == fieldName {
+			if subfield.DataItem == fieldName && seen[fieldName] && !subfield.Repeatable {
 				p.currentPos = originalPos
 				return true
 			}
@@ -132,7 +142,7 @@ func (p *Parser) isStartOfNewItem(subfields []DataField) bool {
 }
 
 // checkForEndMarker checks if the next field is the END marker for the list
-func (p *Parser) checkForEndMarker(fieldName string) bool {
+func (p *parseState) checkForEndMarker(fieldName string) bool {
 	originalPos := p.currentPos
 	p.buffer.Reset()
 
@@ -160,6 +170,7 @@ func (p *Parser) checkForEndMarker(fieldName string) bool {
 	// Consume the field name after END
 	p.buffer.Reset()
 	p.currentPos++ // Skip the space after END
+	endStart := p.currentPos
 	for p.currentPos < len(p.message) && p.message[p.currentPos] != ' ' && p.message[p.currentPos] != '-' {
 		p.buffer.WriteByte(p.message[p.currentPos])
 		p.currentPos++
@@ -167,13 +178,13 @@ func (p *Parser) checkForEndMarker(fieldName string) bool {
 
 	endFieldName := p.buffer.String()
 	if endFieldName != fieldName {
-		log.Printf("Warning: END field name '%s' does not match BEGIN field name '%s'", endFieldName, fieldName)
+		p.warn(endStart, CodeEndMismatch, fmt.Errorf("END field name '%s' does not match BEGIN field name '%s'", endFieldName, fieldName))
 	}
 
 	return true
 }
 
-func (p *Parser) skipUnexpectedField() {
+func (p *parseState) skipUnexpectedField() {
 	for p.currentPos < len(p.message) && p.message[p.currentPos] != '-' {
 		p.currentPos++
 	}
@@ -3,6 +3,7 @@ package adexp
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -73,11 +74,16 @@ func Test_Parse_ListField(t *testing.T) {
 					}
 
 					for i, expectedPt := range expectedPoints {
-						pt, ok := rtepts[i].(map[string]interface{})
+						item, ok := rtepts[i].(map[string]interface{})
 						if !ok {
 							t.Errorf("Expected route point %d to be a map[string]interface{}, got %T", i, rtepts[i])
 							continue
 						}
+						pt, ok := item["PT"].(map[string]interface{})
+						if !ok {
+							t.Errorf("Expected route point %d to hold PT, got %v", i, item)
+							continue
+						}
 
 						for key, expectedValue := range expectedPt {
 							if pt[key] != expectedValue {
@@ -134,3 +140,53 @@ func Test_Parse_ListField(t *testing.T) {
 		})
 	}
 }
+
+func Test_Parse_ListField_Shape(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	content, err := os.ReadFile(filepath.Join("../test/fpl/adexp", "BFD.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	fp, err := NewParserWithOpts(testSchema, ParserOpts{FlattenListItems: true}).Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	expected := map[string]interface{}{"PTID": "WOODY", "TO": "1235", "FL": "F210"}
+	if rtepts, _ := fp["RTEPTS"].([]interface{}); len(rtepts) != 3 || !reflect.DeepEqual(rtepts[0], expected) {
+		t.Errorf("Expected flattened route points starting with %v, got %v", expected, fp["RTEPTS"])
+	}
+}
+
+func Test_Parse_ListField_BasicSubfields(t *testing.T) {
+	schema := MessageSet{Name: "TestSet", Set: map[string]StandardSchema{
+		"TST": {Category: "TST", Items: []DataField{
+			{FRN: 1, DataItem: "TITLE", Type: Basicfield},
+			{FRN: 2, DataItem: "RTEPTS", Type: ListField, Subfields: []DataField{
+				{DataItem: "PTID", Type: Basicfield},
+				{DataItem: "FL", Type: Basicfield},
+				{DataItem: "PT", Type: StructuredField, Subfields: []DataField{
+					{DataItem: "PTID", Type: Basicfield},
+				}},
+			}},
+		}},
+	}}
+
+	for _, flatten := range []bool{false, true} {
+		parser := NewParserWithOpts([]MessageSet{schema}, ParserOpts{FlattenListItems: flatten})
+		fp, err := parser.Parse("-TITLE TST -BEGIN RTEPTS -PTID WOODY -FL F210 -PTID CIV -PT -PTID NEBUL -END RTEPTS")
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		expected := []interface{}{
+			map[string]interface{}{"PTID": "WOODY", "FL": "F210"},
+			map[string]interface{}{"PTID": "CIV", "PT": map[string]interface{}{"PTID": "NEBUL"}},
+		}
+		if flatten {
+			expected[1] = map[string]interface{}{"PTID": "NEBUL"}
+		}
+		if !reflect.DeepEqual(fp["RTEPTS"], expected) {
+			t.Errorf("flatten %v: expected %v, got %v", flatten, expected, fp["RTEPTS"])
+		}
+	}
+}
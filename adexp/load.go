@@ -0,0 +1,251 @@
+package adexp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrorNoCategory = errors.New("schema has no Category")
+var ErrorDuplicateCategory = errors.New("duplicate category")
+var ErrorDuplicateDataItem = errors.New("duplicate DataItem")
+var ErrorUnknownKey = errors.New("unknown key")
+var ErrorInvalidType = errors.New("invalid Type")
+var ErrorEmptySubfields = errors.New("empty Subfields")
+var ErrorInconsistentFRN = errors.New("inconsistent FRN")
+
+// SchemaError reports a problem found in a schema file while loading a message set
+type SchemaError struct {
+	// File is the name of the schema file
+	File string
+	// Path is the JSON path of the offending value, e.g. Items[3].Subfields,
+	// or empty if the problem concerns the whole file
+	Path string
+	Err  error
+}
+
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.File, e.Path, e.Err)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// schemaFile is the content of a schema file: either a StandardSchema or, if
+// it has no Category, catalogue Fields that schemas reference by name
+type schemaFile struct {
+	StandardSchema `yaml:",inline"`
+	Fields         []DataField `yaml:"Fields"`
+}
+
+// loader builds a message set from schema files. Problems that make a file
+// unusable are collected as errors. Unknown keys, duplicate DataItems among
+// the fields of a schema and FRN inconsistencies are collected as warnings,
+// as such files were always accepted.
+type loader struct {
+	set *MessageSet
+	// files holds the file of each catalogue field ("field NAME") and
+	// schema ("schema CATEGORY") loaded
+	files    map[string]string
+	errs     []error
+	warnings []error
+}
+
+func newLoader(name string) *loader {
+	return &loader{
+		set: &MessageSet{
+			Name:      name,
+			Set:       make(map[string]StandardSchema),
+			Catalogue: make(map[string]DataField),
+		},
+		files: make(map[string]string),
+	}
+}
+
+// addJSON checks the schema file and adds its schema or catalogue fields to the set
+func (l *loader) addJSON(file string, content []byte) {
+	var schema schemaFile
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&schema); err != nil {
+		schema = schemaFile{}
+		if json.Unmarshal(content, &schema) != nil {
+			l.errs = append(l.errs, &SchemaError{File: file, Err: err})
+			return
+		}
+		l.warnings = append(l.warnings, &SchemaError{File: file, Err: fmt.Errorf("%w: %v", ErrorUnknownKey, err)})
+	}
+	l.add(file, schema)
+}
+
+// addYAML checks the YAML schema file and adds its schema or catalogue fields to the set
+func (l *loader) addYAML(file string, content []byte) {
+	var schema schemaFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&schema); err != nil && err != io.EOF {
+		schema = schemaFile{}
+		if yaml.Unmarshal(content, &schema) != nil {
+			l.errs = append(l.errs, &SchemaError{File: file, Err: err})
+			return
+		}
+		l.warnings = append(l.warnings, &SchemaError{File: file, Err: fmt.Errorf("%w: %v", ErrorUnknownKey, err)})
+	}
+	l.add(file, schema)
+}
+
+// isSchemaFile reports whether name has the extension of a schema file:
+// .json, .yaml or .yml
+func isSchemaFile(name string) bool {
+	switch path.Ext(name) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// addFile adds the JSON or YAML schema file by its extension
+func (l *loader) addFile(file string, content []byte) {
+	if path.Ext(file) == ".json" {
+		l.addJSON(file, content)
+		return
+	}
+	l.addYAML(file, content)
+}
+
+// addFS adds the schema files of fsys and its subdirectories, named by their
+// path in fsys
+func (l *loader) addFS(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() || !isSchemaFile(name) {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		l.addFile(name, content)
+		return nil
+	})
+}
+
+// add checks a decoded schema file and adds it to the set
+func (l *loader) add(file string, schema schemaFile) {
+	if schema.Category == "" {
+		if len(schema.Fields) == 0 {
+			l.errs = append(l.errs, &SchemaError{File: file, Err: ErrorNoCategory})
+			return
+		}
+		if !l.checkFields(file, "Fields", schema.Fields, false) {
+			return
+		}
+		for i, field := range schema.Fields {
+			key := "field " + field.DataItem
+			if other, ok := l.files[key]; ok {
+				l.errs = append(l.errs, &SchemaError{File: file, Path: fmt.Sprintf("Fields[%d].DataItem", i),
+					Err: fmt.Errorf("%w '%s', also defined in %s", ErrorDuplicateDataItem, field.DataItem, other)})
+				continue
+			}
+			l.files[key] = file
+			l.set.Catalogue[field.DataItem] = field
+		}
+		return
+	}
+
+	key := "schema " + schema.Category
+	if other, ok := l.files[key]; ok {
+		l.errs = append(l.errs, &SchemaError{File: file, Path: "Category",
+			Err: fmt.Errorf("%w '%s', also defined in %s", ErrorDuplicateCategory, schema.Category, other)})
+		return
+	}
+	if l.checkFields(file, "Items", schema.Items, true) {
+		l.files[key] = file
+		l.set.Set[schema.Category] = schema.StandardSchema
+	}
+}
+
+// checkFields checks the fields found at path and their subfields. It
+// returns false if an error was found.
+func (l *loader) checkFields(file, path string, fields []DataField, numbered bool) bool {
+	ok := true
+	fail := func(path string, err error) {
+		l.errs = append(l.errs, &SchemaError{File: file, Path: path, Err: err})
+		ok = false
+	}
+
+	names := make(map[string]int, len(fields))
+	var previousFRN uint8
+	for i, field := range fields {
+		fieldPath := fmt.Sprintf("%s[%d]", path, i)
+
+		name := field.DataItem
+		if name == "" {
+			name = field.Ref
+		}
+		if first, seen := names[name]; seen {
+			// The parser uses the first field of a name
+			l.warnings = append(l.warnings, &SchemaError{File: file, Path: fieldPath + ".DataItem",
+				Err: fmt.Errorf("%w '%s', also at %s[%d]", ErrorDuplicateDataItem, name, path, first)})
+		} else {
+			names[name] = i
+		}
+
+		if numbered {
+			switch {
+			case field.FRN == 0:
+				l.warnings = append(l.warnings, &SchemaError{File: file, Path: fieldPath + ".FRN", Err: fmt.Errorf("%w: missing", ErrorInconsistentFRN)})
+			case field.FRN <= previousFRN:
+				l.warnings = append(l.warnings, &SchemaError{File: file, Path: fieldPath + ".FRN",
+					Err: fmt.Errorf("%w: %d does not follow %d", ErrorInconsistentFRN, field.FRN, previousFRN)})
+			}
+			previousFRN = max(previousFRN, field.FRN)
+		}
+
+		if field.Ref != "" {
+			// The type and subfields come from the catalogue
+			if field.Subfields != nil && len(field.Subfields) == 0 {
+				fail(fieldPath+".Subfields", ErrorEmptySubfields)
+			}
+		} else {
+			switch field.Type {
+			case Basicfield:
+			case ListField, StructuredField:
+				if len(field.Subfields) == 0 {
+					fail(fieldPath+".Subfields", fmt.Errorf("%w for %s", ErrorEmptySubfields, field.DataItem))
+				}
+			default:
+				fail(fieldPath+".Type", fmt.Errorf("%w %d, expected 0 (basic), 1 (list) or 2 (structured)", ErrorInvalidType, field.Type))
+			}
+		}
+
+		if len(field.Subfields) > 0 && !l.checkFields(file, fieldPath+".Subfields", field.Subfields, true) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// finish resolves the loaded set against base and returns it with the
+// warnings, or all errors found
+func (l *loader) finish(base *MessageSet) (*MessageSet, []error, error) {
+	if len(l.errs) > 0 {
+		return nil, nil, errors.Join(l.errs...)
+	}
+	if len(l.set.Set) == 0 {
+		return nil, nil, errors.New("length of set is 0")
+	}
+	if err := l.set.resolve(base, l.files); err != nil {
+		return nil, nil, err
+	}
+	return l.set, l.warnings, nil
+}
@@ -2,69 +2,152 @@ package adexp
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 	"unicode"
 )
 
-// Parser represents the ADEXP message parser
+// Parser represents the ADEXP message parser. It holds no per-message state,
+// so a single Parser can be used by several goroutines at once as long as
+// MessageSet and Opts are not modified meanwhile.
 type Parser struct {
-	MessageSet    []MessageSet
+	MessageSet []MessageSet
+	Opts       ParserOpts
+}
+
+// parseState holds the state of parsing a single message
+type parseState struct {
+	*Parser
 	buffer        bytes.Buffer
 	currentPos    int
 	message       string
+	original      string
+	offset        int
+	path          []string
+	warnings      []error
 	flightplan    map[string]interface{}
 	currentSchema *StandardSchema
 }
 
+// ParseMode selects how the Parser handles malformed messages
+type ParseMode uint8
+
+const (
+	// ModeStrict rejects messages containing invalid characters or fields
+	// of an unsupported type
+	ModeStrict ParseMode = iota
+	// ModeLenient reports these problems as warnings and keeps parsing
+	ModeLenient
+)
+
+// ParserOpts configures the checks applied by the Parser
+type ParserOpts struct {
+	Mode ParseMode
+	// Mandatory controls how missing mandatory fields are reported
+	Mandatory MandatoryPolicy
+	// FlattenListItems merges the structured subfields of list items into
+	// the item, e.g. RTEPTS items become {"PTID": ...} instead of
+	// {"PT": {"PTID": ...}}
+	FlattenListItems bool
+	// IgnoreTargets returns values under their DataItem instead of the
+	// Target path of their field, as Marshal also accepts them
+	IgnoreTargets bool
+	// Version selects the version of the schemas used, e.g. "3.4". Titles
+	// without a schema of this version are rejected. If empty, the first
+	// schema of the title found in MessageSet is used.
+	Version string
+	// Routes select the schema version or message set of the messages they
+	// match instead of Version. The first matching route is used.
+	Routes []SchemaRoute
+}
+
 // NewParser creates a new Parser instance with the given schema
 func NewParser(schema []MessageSet) *Parser {
+	return NewParserWithOpts(schema, ParserOpts{})
+}
+
+// NewParserWithOpts creates a new Parser instance with the given schema and options
+func NewParserWithOpts(schema []MessageSet, opts ParserOpts) *Parser {
 	return &Parser{
 		MessageSet: schema,
-		flightplan: make(map[string]interface{}),
+		Opts:       opts,
 	}
 }
 
 // Parse parses the given ADEXP message and returns a map representation of the flight plan
 func (p *Parser) Parse(message string) (map[string]interface{}, error) {
-	p.currentPos = 0
-	p.message = strings.ReplaceAll(message, "\n", " ")
-	p.message = strings.TrimSpace(p.message)
+	fp, _, err := p.ParseWithWarnings(message)
+	return fp, err
+}
+
+// ParseWithWarnings parses the given ADEXP message like Parse and also returns
+// the problems that did not stop the parse as *ParseError or
+// *MissingFieldError: unknown and duplicate fields, END names not matching
+// their BEGIN, missing mandatory fields under MandatoryWarn and, in
+// ModeLenient, invalid characters, fields of an unsupported type and values
+// not matching the Format of their field, which are kept as strings.
+func (p *Parser) ParseWithWarnings(message string) (map[string]interface{}, []error, error) {
+	state := &parseState{Parser: p, original: message}
+	return state.parse()
+}
+
+// parse parses the message the state was created for
+func (p *parseState) parse() (map[string]interface{}, []error, error) {
+	// Newlines are replaced one for one, so positions only shift by the
+	// leading whitespace trimmed here
+	p.message = strings.ReplaceAll(p.original, "\n", " ")
+	p.offset = len(p.message)
+	p.message = strings.TrimLeftFunc(p.message, unicode.IsSpace)
+	p.offset -= len(p.message)
+	p.message = strings.TrimRightFunc(p.message, unicode.IsSpace)
 	p.message = strings.TrimSuffix(p.message, "NNNN")
 	p.flightplan = make(map[string]interface{})
 
 	if err := p.validateMessage(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := p.findTitle(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	for p.currentPos < len(p.message) {
 		if err := p.parseNextField(); err != nil {
-			return nil, fmt.Errorf("error parsing field: %w", err)
+			return nil, nil, err
 		}
 	}
 
-	return p.flightplan, nil
+	warnings, err := p.checkMandatory(p.flightplan)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !p.Opts.IgnoreTargets {
+		applyTargets(p.currentSchema.Items, p.flightplan, p.flightplan)
+	}
+
+	return p.flightplan, append(p.warnings, warnings...), nil
 }
 
 // validateMessage checks if the message contains only valid characters
-func (p *Parser) validateMessage() error {
-	for _, char := range p.message {
+func (p *parseState) validateMessage() error {
+	for i, char := range p.message {
 		if !isValidCharacter(char) {
-			return fmt.Errorf("invalid character '%v' found in message", string(char))
+			err := p.errorAt(i, CodeInvalidCharacter, fmt.Errorf("invalid character '%v' found in message", string(char)))
+			if p.Opts.Mode != ModeLenient {
+				return err
+			}
+			p.warnings = append(p.warnings, err)
 		}
 	}
 	return nil
 }
 
 // findTitle locates the TITLE field and sets the appropriate schema
-func (p *Parser) findTitle() error {
+func (p *parseState) findTitle() error {
 	titleStart := strings.Index(p.message, "-TITLE ")
 	if titleStart == -1 {
-		return fmt.Errorf("TITLE field not found in the message")
+		return &ParseError{Code: CodeNoTitle, Err: ErrorNoTitle}
 	}
 
 	titleStart += 7 // Length of "-TITLE "
@@ -78,13 +161,13 @@ func (p *Parser) findTitle() error {
 
 	matchedSchema, err := p.findMatchingSchema(title)
 	if err != nil {
-		return err
+		return p.errorAt(titleStart, CodeNoSchema, err)
 	}
 	p.currentSchema = matchedSchema
 	return nil
 }
 
-func (p *Parser) parseNextField() error {
+func (p *parseState) parseNextField() error {
 	p.buffer.Reset()
 	for p.currentPos < len(p.message) && p.message[p.currentPos] != '-' {
 		p.currentPos++
@@ -94,6 +177,7 @@ func (p *Parser) parseNextField() error {
 		return nil // End of message
 	}
 
+	fieldStart := p.currentPos
 	p.currentPos++ // Skip the '-'
 	for p.currentPos < len(p.message) && p.message[p.currentPos] != ' ' {
 		p.buffer.WriteByte(p.message[p.currentPos])
@@ -110,32 +194,40 @@ func (p *Parser) parseNextField() error {
 	field := p.findField(fieldName, p.currentSchema.Items)
 	if field == nil {
 		// If the field is not found in the schema, we'll skip it
+		p.warn(fieldStart, CodeUnknownField, fmt.Errorf("unknown field '%s'", fieldName))
 		p.skipUnknownField()
 		return nil
 	}
 
 	switch field.Type {
 	case Basicfield:
-		key, value, err := p.parseBasicField(*field)
+		_, value, err := p.parseBasicField(*field)
 		if err != nil {
 			return err
 		}
-		p.flightplan[key] = value
+		p.setField(p.flightplan, fieldStart, field, value)
 	case StructuredField:
-		key, value, err := p.parseStructuredField(*field)
+		_, value, err := p.parseStructuredField(*field)
 		if err != nil {
 			return err
 		}
-		p.flightplan[key] = value
+		p.setField(p.flightplan, fieldStart, field, value)
 	default:
-		return fmt.Errorf("unknown field type for field '%s'", fieldName)
+		p.pushField(fieldName)
+		defer p.popField()
+		err := p.errorAt(fieldStart, CodeInvalidField, fmt.Errorf("unknown field type for field '%s'", fieldName))
+		if p.Opts.Mode != ModeLenient {
+			return err
+		}
+		p.warnings = append(p.warnings, err)
+		p.skipUnknownField()
 	}
 
 	return nil
 }
 
 // findField finds a field in the given slice of DataFields
-func (p *Parser) findField(fieldName string, fields []DataField) *DataField {
+func (p *parseState) findField(fieldName string, fields []DataField) *DataField {
 	for i := range fields {
 		if fields[i].DataItem == fieldName {
 			return &fields[i]
@@ -144,20 +236,58 @@ func (p *Parser) findField(fieldName string, fields []DataField) *DataField {
 	return nil
 }
 
-// findMatchingSchema finds the matching schema for the given title
-func (p *Parser) findMatchingSchema(title string) (*StandardSchema, error) {
-	for _, messageSet := range p.MessageSet {
-		for _, schema := range messageSet.Set {
-			if schema.Category == title {
+// findMatchingSchema finds the matching schema for the given title, in the
+// version selected by the first matching route or by Opts.Version
+func (p *parseState) findMatchingSchema(title string) (*StandardSchema, error) {
+	for _, route := range p.Opts.Routes {
+		if route.matches(title, p.message) {
+			return selectSchema(p.MessageSet, title, route.Version, route.MessageSet)
+		}
+	}
+	return selectSchema(p.MessageSet, title, p.Opts.Version, "")
+}
+
+// findSchema finds the schema for the given title in a list of message sets.
+// Sets are searched in order, so earlier sets override later ones.
+func findSchema(sets []MessageSet, title string) (*StandardSchema, error) {
+	return selectSchema(sets, title, "", "")
+}
+
+// selectSchema finds the schema for the given title like findSchema, only
+// considering schemas of version and sets named set if they are not empty
+func selectSchema(sets []MessageSet, title, version, set string) (*StandardSchema, error) {
+	for _, messageSet := range sets {
+		if set != "" && messageSet.Name != set {
+			continue
+		}
+		// Sets are keyed by category, other keys are searched in order
+		// so that the match does not depend on the map order
+		if schema, ok := messageSet.Set[title]; ok && schema.Category == title && matchesVersion(schema, version) {
+			return &schema, nil
+		}
+		for _, key := range sortedKeys(messageSet.Set) {
+			if schema := messageSet.Set[key]; schema.Category == title && matchesVersion(schema, version) {
 				return &schema, nil
 			}
 		}
 	}
-	return nil, fmt.Errorf("no matching schema found for title: %s", title)
+
+	msg := "no matching schema found for title: " + title
+	if version != "" {
+		msg += " version " + version
+	}
+	if set != "" {
+		msg += " in message set " + set
+	}
+	return nil, errors.New(msg)
+}
+
+func matchesVersion(schema StandardSchema, version string) bool {
+	return version == "" || schema.Version == version
 }
 
 // skipUnknownField skips an unknown field in the message
-func (p *Parser) skipUnknownField() {
+func (p *parseState) skipUnknownField() {
 	for p.currentPos < len(p.message) && p.message[p.currentPos] != '-' {
 		p.currentPos++
 	}
@@ -178,9 +308,13 @@ func isValidCharacter(char rune) bool {
 }
 
 // handleListField handles the parsing of a list field
-func (p *Parser) handleListField() error {
+func (p *parseState) handleListField() error {
+	beginStart := p.currentPos - len("-BEGIN")
 	// Skip the space after "BEGIN"
-	p.currentPos++
+	if p.currentPos < len(p.message) {
+		p.currentPos++
+	}
+	listStart := p.currentPos
 
 	// Read the list field name
 	p.buffer.Reset()
@@ -189,17 +323,30 @@ func (p *Parser) handleListField() error {
 		p.currentPos++
 	}
 	listFieldName := p.buffer.String()
+	if listFieldName == "" {
+		err := p.errorAt(beginStart, CodeInvalidField, fmt.Errorf("BEGIN without a list name"))
+		if p.Opts.Mode != ModeLenient {
+			return err
+		}
+		p.warnings = append(p.warnings, err)
+		return nil
+	}
 
 	field := p.findField(listFieldName, p.currentSchema.Items)
 	if field == nil || field.Type != ListField {
+		p.warn(listStart, CodeUnknownField, fmt.Errorf("unknown list field '%s'", listFieldName))
+		// Skip the whole list rather than each of its items
+		if end := strings.Index(p.message[p.currentPos:], "-END "+listFieldName); end != -1 {
+			p.currentPos += end + len("-END "+listFieldName)
+		}
 		return nil
 	}
 
-	key, value, err := p.parseListField(*field)
+	_, value, err := p.parseListField(*field)
 	if err != nil {
 		return fmt.Errorf("error parsing list field '%s': %w", listFieldName, err)
 	}
-	p.flightplan[key] = value
+	p.setField(p.flightplan, listStart, field, value)
 
 	return nil
 }
@@ -1,9 +1,12 @@
 package adexp
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -87,11 +90,16 @@ func Test_Parse(t *testing.T) {
 					}
 
 					for i, expectedPt := range expectedPoints {
-						pt, ok := rtepts[i].(map[string]interface{})
+						item, ok := rtepts[i].(map[string]interface{})
 						if !ok {
 							t.Errorf("Expected route point %d to be a map[string]interface{}, got %T", i, rtepts[i])
 							continue
 						}
+						pt, ok := item["PT"].(map[string]interface{})
+						if !ok {
+							t.Errorf("Expected route point %d to hold PT, got %v", i, item)
+							continue
+						}
 
 						for key, expectedValue := range expectedPt {
 							if pt[key] != expectedValue {
@@ -256,6 +264,40 @@ func Test_Parse(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "ACT message with nested lists",
+			filename: "ACT.txt",
+			expected: func(t *testing.T, fp map[string]interface{}) {
+				refdata, ok := fp["REFDATA"].(map[string]interface{})
+				if !ok {
+					t.Fatalf("Expected REFDATA to be a map[string]interface{}, got %T", fp["REFDATA"])
+				}
+				expectedAddr := []interface{}{"EDDFZQZX", "EDDMZQZX"}
+				if !reflect.DeepEqual(refdata["ADDR"], expectedAddr) {
+					t.Errorf("Expected REFDATA.ADDR to be %v, got %v", expectedAddr, refdata["ADDR"])
+				}
+				if refdata["SEQNUM"] != "012" {
+					t.Errorf("Expected REFDATA.SEQNUM to be 012, got %v", refdata["SEQNUM"])
+				}
+				if fp["ARCID"] != "DLH151" {
+					t.Errorf("Expected ARCID to be DLH151, got %v", fp["ARCID"])
+				}
+
+				expectedRtepts := []interface{}{
+					map[string]interface{}{"PT": map[string]interface{}{
+						"PTID": "WOODY", "TO": "1235", "FL": "F210",
+						"CONSTRAINTS": []interface{}{
+							map[string]interface{}{"CONSTR": map[string]interface{}{"FL": "F200", "COND": "AT OR ABOVE"}},
+							map[string]interface{}{"CONSTR": map[string]interface{}{"FL": "F240", "COND": "AT OR BELOW"}},
+						},
+					}},
+					map[string]interface{}{"PT": map[string]interface{}{"PTID": "CIV", "TO": "1239", "FL": "F330"}},
+				}
+				if !reflect.DeepEqual(fp["RTEPTS"], expectedRtepts) {
+					t.Errorf("Expected RTEPTS to be %v, got %v", expectedRtepts, fp["RTEPTS"])
+				}
+			},
+		},
 		{
 			name:     "SLC message",
 			filename: "SLC.txt",
@@ -403,8 +445,8 @@ func Test_Parse_Errors(t *testing.T) {
 			filename:    "ADEXP_no_schema.txt",
 			expectError: true,
 			expected: func(t *testing.T, err error) {
-				if err == nil || err.Error() != "no matching schema found for title: ABC" {
-					t.Errorf("Expected error 'no matching schema found for title: ABC', got: %v", err)
+				if err == nil || err.Error() != "line 1, column 8: no matching schema found for title: ABC" {
+					t.Errorf("Expected error 'line 1, column 8: no matching schema found for title: ABC', got: %v", err)
 				}
 			},
 		},
@@ -423,11 +465,13 @@ func Test_Parse_Errors(t *testing.T) {
 			filename:    "ADEXP_invalid_char.txt",
 			expectError: true,
 			expected: func(t *testing.T, err error) {
-				// The behavior for invalid characters might need to be defined
-				if err == nil {
-					t.Errorf("Expected an error for invalid characters, got nil")
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("Expected a ParseError, got: %v", err)
+				}
+				if parseErr.Code != CodeInvalidCharacter || parseErr.Offset != 30 || parseErr.Line != 1 || parseErr.Column != 31 {
+					t.Errorf("Expected INVALID_CHARACTER at offset 30, line 1, column 31, got: %+v", parseErr)
 				}
-				fmt.Println(err)
 			},
 		},
 	}
@@ -465,3 +509,226 @@ func LoadTestMessageSet(t *testing.T) MessageSet {
 	}
 	return *messageSet
 }
+
+func Test_ParseError(t *testing.T) {
+	schema := MessageSet{Name: "TestSet", Set: map[string]StandardSchema{
+		"TST": {Category: "TST", Items: []DataField{
+			{FRN: 1, DataItem: "TITLE", Type: Basicfield},
+			{FRN: 2, DataItem: "REFDATA", Type: StructuredField, Subfields: []DataField{
+				{DataItem: "SENDER", Type: StructuredField, Subfields: []DataField{
+					{DataItem: "FAC", Type: Basicfield},
+					{DataItem: "BAD", Type: 9},
+				}},
+			}},
+		}},
+	}}
+	parser := NewParser([]MessageSet{schema})
+
+	testCases := []struct {
+		name     string
+		message  string
+		expected ParseError
+	}{
+		{
+			name:     "nested field",
+			message:  "\n -TITLE TST\n-REFDATA\n  -SENDER -FAC EBBUZXZQ\n  -BAD X",
+			expected: ParseError{Offset: 48, Line: 5, Column: 3, Path: "REFDATA.SENDER", Code: CodeInvalidField},
+		},
+		{
+			name:     "no title",
+			message:  "-ARCID ABC",
+			expected: ParseError{Code: CodeNoTitle},
+		},
+		{
+			name:     "no schema",
+			message:  "-TITLE ABC\n-ARCID ABC",
+			expected: ParseError{Offset: 7, Line: 1, Column: 8, Code: CodeNoSchema},
+		},
+		{
+			name:     "invalid character",
+			message:  "-TITLE TST\r\n-REFDATA -SENDER -FAC ab",
+			expected: ParseError{Offset: 34, Line: 2, Column: 23, Code: CodeInvalidCharacter},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.Parse(tc.message)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a ParseError, got: %v", err)
+			}
+			if parseErr.Offset != tc.expected.Offset || parseErr.Line != tc.expected.Line ||
+				parseErr.Column != tc.expected.Column || parseErr.Path != tc.expected.Path || parseErr.Code != tc.expected.Code {
+				t.Errorf("Expected %+v, got %+v", tc.expected, *parseErr)
+			}
+		})
+	}
+}
+
+func Test_Parse_Lenient(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	message := "-TITLE BFD\n-REFDATA -SENDER -FAC EBBUZXZQ -RECVR -FAC EBSZZXZQ -SEQNUM 006\n" +
+		"-ARCID DLH151\n-ARCID DLH152\n-ADEP EDDW\n-ADES GMME\n-FOO BAR\n" +
+		"-BEGIN EQCST -EQPT W/EQ -END EQCSTX\n-RMK ONE ENG *INOP"
+
+	if _, err := NewParser(testSchema).Parse(message); err == nil {
+		t.Fatalf("Expected strict mode to reject invalid characters")
+	}
+
+	parser := NewParserWithOpts(testSchema, ParserOpts{Mode: ModeLenient})
+	fp, warnings, err := parser.ParseWithWarnings(message)
+	if err != nil {
+		t.Fatalf("ParseWithWarnings failed: %v", err)
+	}
+	if fp["ARCID"] != "DLH152" || fp["ADEP"] != "EDDW" || fp["ADES"] != "GMME" {
+		t.Errorf("Expected ARCID, ADEP and ADES to be parsed, got %v", fp)
+	}
+	if fp["RMK"] != "ONE ENG *INOP" {
+		t.Errorf("Expected RMK to be kept as sent, got %v", fp["RMK"])
+	}
+
+	expected := []struct {
+		code ErrorCode
+		line int
+	}{
+		{CodeInvalidCharacter, 9},
+		{CodeDuplicateField, 4},
+		{CodeUnknownField, 7},
+		{CodeEndMismatch, 8},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings, got %d: %v", len(expected), len(warnings), warnings)
+	}
+	for i, exp := range expected {
+		var parseErr *ParseError
+		if !errors.As(warnings[i], &parseErr) {
+			t.Fatalf("Expected warning %d to be a ParseError, got %T", i, warnings[i])
+		}
+		if parseErr.Code != exp.code || parseErr.Line != exp.line {
+			t.Errorf("Expected warning %d to be %s on line %d, got %v", i, exp.code, exp.line, parseErr)
+		}
+	}
+}
+
+func Test_Parse_Truncated(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	testCases := []struct {
+		message string
+		code    ErrorCode
+	}{
+		{message: "-TITLE BFD -ARCID X -BEGIN", code: CodeInvalidField},
+		{message: "-TITLE BFD -ARCID X -BEGIN ", code: CodeInvalidField},
+		{message: "-TITLE SAM -TTO -BEGIN", code: CodeInvalidField},
+		{message: "-TITLE SAM -TTO -PTID(GZO", code: CodeUnknownField},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			_, err := NewParser(testSchema).Parse(tc.message)
+			var parseErr *ParseError
+			if tc.code == CodeInvalidField && (!errors.As(err, &parseErr) || parseErr.Code != tc.code) {
+				t.Errorf("Expected a %s error in strict mode, got %v", tc.code, err)
+			}
+
+			_, warnings, err := NewParserWithOpts(testSchema, ParserOpts{Mode: ModeLenient}).ParseWithWarnings(tc.message)
+			if err != nil {
+				t.Fatalf("Expected lenient mode to accept the message, got %v", err)
+			}
+			if len(warnings) == 0 || !errors.As(warnings[0], &parseErr) || parseErr.Code != tc.code {
+				t.Errorf("Expected a %s warning, got %v", tc.code, warnings)
+			}
+		})
+	}
+
+	// No prefix of a valid message may panic
+	content, err := os.ReadFile("../test/fpl/adexp/BFD.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	for _, mode := range []ParseMode{ModeStrict, ModeLenient} {
+		parser := NewParserWithOpts(testSchema, ParserOpts{Mode: mode})
+		for i := range content {
+			parser.ParseWithWarnings(string(content[:i]))
+		}
+	}
+}
+
+func Test_Parse_Concurrent(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	parser := NewParserWithOpts(testSchema, ParserOpts{Mode: ModeLenient})
+
+	files := []string{"BFD.txt", "CFD.txt", "TFD.txt", "SAM.txt", "SRM.txt", "SLC.txt", "FLS.txt", "DES.txt"}
+	messages := make([]string, len(files))
+	expected := make([]map[string]interface{}, len(files))
+	for i, file := range files {
+		content, err := os.ReadFile(filepath.Join("../test/fpl/adexp", file))
+		if err != nil {
+			t.Fatalf("Failed to read test file: %v", err)
+		}
+		messages[i] = string(content)
+		expected[i], err = parser.Parse(messages[i])
+		if err != nil {
+			t.Fatalf("Parse of %s failed: %v", file, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*len(files))
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				i := (g + n) % len(files)
+				fp, err := parser.Parse(messages[i])
+				if err != nil {
+					errs <- fmt.Errorf("%s: %w", files[i], err)
+					return
+				}
+				if !reflect.DeepEqual(fp, expected[i]) {
+					errs <- fmt.Errorf("%s: result differs from the sequential parse", files[i])
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func Benchmark_Parse(b *testing.B) {
+	set, err := MessageSetFromJSON("../test/schema", "TestSet")
+	if err != nil {
+		b.Fatalf("Failed to load message set from JSON: %v", err)
+	}
+	parser := NewParser([]MessageSet{*set})
+	content, err := os.ReadFile("../test/fpl/adexp/BFD.txt")
+	if err != nil {
+		b.Fatalf("Failed to read test file: %v", err)
+	}
+	message := string(content)
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.Parse(message); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := parser.Parse(message); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
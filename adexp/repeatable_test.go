@@ -0,0 +1,70 @@
+package adexp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func repeatableTestSchema() StandardSchema {
+	return StandardSchema{Category: "TST", Items: []DataField{
+		{FRN: 1, DataItem: "TITLE", Type: Basicfield},
+		{FRN: 2, DataItem: "ARCID", Type: Basicfield},
+		{FRN: 3, DataItem: "EETFIR", Type: Basicfield, Repeatable: true},
+		{FRN: 4, DataItem: "ESTDATA", Type: StructuredField, Repeatable: true, Subfields: []DataField{
+			{FRN: 1, DataItem: "PTID", Type: Basicfield, Mendatory: true},
+			{FRN: 2, DataItem: "ETO", Type: Basicfield},
+		}},
+		{FRN: 5, DataItem: "REFDATA", Type: StructuredField, Subfields: []DataField{
+			{FRN: 1, DataItem: "RECVR", Type: Basicfield, Repeatable: true},
+		}},
+		{FRN: 6, DataItem: "RMK", Type: Basicfield, Repeatable: true},
+	}}
+}
+
+func Test_Parse_Repeatable(t *testing.T) {
+	schema := repeatableTestSchema()
+	parser := NewParser([]MessageSet{{Name: "TestSet", Set: map[string]StandardSchema{"TST": schema}}})
+
+	message := "-TITLE TST\n-ARCID ABC123\n-EETFIR EDUUFIR 0035\n-EETFIR EDVVFIR 0102\n" +
+		"-ESTDATA -PTID WOODY -ETO 240101120000\n-ESTDATA -ETO 240101121000\n" +
+		"-REFDATA -RECVR EDDFZQZX -RECVR EDDMZQZX\n-RMK FIRST\n-ARCID ABC124"
+	fp, warnings, err := parser.ParseWithWarnings(message)
+	if err != nil {
+		t.Fatalf("ParseWithWarnings failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"TITLE":  "TST",
+		"ARCID":  "ABC124",
+		"EETFIR": []interface{}{"EDUUFIR 0035", "EDVVFIR 0102"},
+		"ESTDATA": []interface{}{
+			map[string]interface{}{"PTID": "WOODY", "ETO": "240101120000"},
+			map[string]interface{}{"ETO": "240101121000"},
+		},
+		"REFDATA": map[string]interface{}{"RECVR": []interface{}{"EDDFZQZX", "EDDMZQZX"}},
+		"RMK":     []interface{}{"FIRST"},
+	}
+	if !reflect.DeepEqual(fp, expected) {
+		t.Errorf("Expected %v, got %v", expected, fp)
+	}
+
+	// ARCID is not repeatable, so only its duplicate and the missing PTID of
+	// the second ESTDATA are reported
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+	if missing, ok := warnings[1].(*MissingFieldError); !ok || missing.Path != "ESTDATA[1].PTID" {
+		t.Errorf("Expected ESTDATA[1].PTID to be missing, got %v", warnings[1])
+	}
+
+	s, err := Marshal(fp, schema)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expectedText := "-TITLE TST\n-ARCID ABC124\n-EETFIR EDUUFIR 0035\n-EETFIR EDVVFIR 0102\n" +
+		"-ESTDATA -PTID WOODY -ETO 240101120000\n-ESTDATA -ETO 240101121000\n" +
+		"-REFDATA -RECVR EDDFZQZX -RECVR EDDMZQZX\n-RMK FIRST\n"
+	if s != expectedText {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expectedText, s)
+	}
+}
@@ -0,0 +1,79 @@
+package adexp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SchemaRoute selects the schema version or message set used for the
+// messages it matches, see ParserOpts.Routes. Empty conditions match any
+// message.
+type SchemaRoute struct {
+	// Title matches the TITLE of the message
+	Title string
+	// Sender matches the FAC of the SENDER (REFDATA) or ORIGIN of the message
+	Sender string
+	// Field and Value match a version marker: the first occurrence of the
+	// basic field Field has to hold Value, e.g. Field "VERSION" and Value
+	// "34". An empty Value only requires the field to be present.
+	Field string
+	Value string
+
+	// Version selects the schemas of this version, any version if empty
+	Version string
+	// MessageSet selects the message set of this name, any set if empty
+	MessageSet string
+}
+
+// matches reports whether the message with the given title matches the route
+func (r SchemaRoute) matches(title, message string) bool {
+	if r.Title != "" && r.Title != title {
+		return false
+	}
+	if r.Sender != "" && r.Sender != messageSender(message) {
+		return false
+	}
+	if r.Field != "" {
+		value, ok := scanField(message, r.Field)
+		if !ok || (r.Value != "" && value != r.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// messageSender returns the FAC of the SENDER or ORIGIN of the message, or
+// an empty string if it has none
+func messageSender(message string) string {
+	for _, name := range []string{"-SENDER ", "-ORIGIN "} {
+		if i := strings.Index(message, name); i != -1 {
+			value, _ := scanField(message[i:], "FAC")
+			return value
+		}
+	}
+	return ""
+}
+
+// scanField returns the value of the first occurrence of the field name in
+// the message without parsing it, and whether the field was found
+func scanField(message, name string) (string, bool) {
+	marker := "-" + name
+	for i := 0; ; {
+		j := strings.Index(message[i:], marker)
+		if j == -1 {
+			return "", false
+		}
+		end := i + j + len(marker)
+		if end == len(message) || unicode.IsSpace(rune(message[end])) {
+			value := strings.TrimLeftFunc(message[end:], unicode.IsSpace)
+			if k := strings.IndexFunc(value, unicode.IsSpace); k != -1 {
+				value = value[:k]
+			}
+			if strings.HasPrefix(value, "-") {
+				value = ""
+			}
+			return value, true
+		}
+		i = end
+	}
+}
@@ -0,0 +1,107 @@
+package adexp
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_Parser_Version(t *testing.T) {
+	v34, err := NewMessageSetBuilder("adexp34").Schema(StandardSchema{Category: "SAM", Version: "3.4", Extends: "SAM", Items: []DataField{
+		{FRN: 30, DataItem: "SLOTREF", Type: Basicfield},
+	}}).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	sets := WithStandard(*v34)
+	message := "-TITLE SAM -ARCID AMC101 -ADEP EGLL -ADES LMML -EOBT 0945 -ORIGIN -NETWORKTYPE SITA -FAC EGLLZPZX -SLOTREF X1"
+
+	tests := []struct {
+		name    string
+		opts    ParserOpts
+		slotref bool
+		err     string
+	}{
+		{name: "first set", slotref: true},
+		{name: "version", opts: ParserOpts{Version: "3.1"}},
+		{name: "unknown version", opts: ParserOpts{Version: "9.9"}, err: "line 1, column 8: no matching schema found for title: SAM version 9.9"},
+		{name: "route by sender", opts: ParserOpts{Version: "3.4", Routes: []SchemaRoute{{Sender: "EGLLZPZX", Version: "3.1"}}}},
+		{name: "route by other sender", opts: ParserOpts{Version: "3.1", Routes: []SchemaRoute{{Sender: "LFPYZMZX", Version: "3.4"}}}},
+		{name: "route by marker", opts: ParserOpts{Version: "3.1", Routes: []SchemaRoute{{Title: "SAM", Field: "SLOTREF", Version: "3.4"}}}, slotref: true},
+		{name: "route by message set", opts: ParserOpts{Routes: []SchemaRoute{{Title: "SAM", MessageSet: StandardName}}}},
+		{name: "route by title", opts: ParserOpts{Routes: []SchemaRoute{{Title: "SRM", Version: "3.1"}, {MessageSet: "nope"}}}, err: "line 1, column 8: no matching schema found for title: SAM in message set nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp, err := NewParserWithOpts(sets, tt.opts).Parse(message)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if _, ok := fp["SLOTREF"]; ok != tt.slotref {
+				t.Errorf("Expected SLOTREF %v, got %v", tt.slotref, fp)
+			}
+		})
+	}
+
+	var b strings.Builder
+	enc := NewEncoder(&b, sets)
+	enc.Version = "3.1"
+	if err := enc.Encode(map[string]interface{}{"TITLE": "SAM", "ARCID": "AMC101", "SLOTREF": "X1"}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if strings.Contains(b.String(), "SLOTREF") {
+		t.Errorf("Expected the 3.1 schema to leave SLOTREF out, got %s", b.String())
+	}
+}
+
+func Test_FindSchema_Order(t *testing.T) {
+	set := MessageSet{Set: map[string]StandardSchema{
+		"XYZ_C": {Category: "XYZ", Version: "C"},
+		"XYZ_A": {Category: "XYZ", Version: "A"},
+		"XYZ_B": {Category: "XYZ", Version: "B"},
+	}}
+	for i := 0; i < 20; i++ {
+		schema, err := findSchema([]MessageSet{set}, "XYZ")
+		if err != nil || schema.Version != "A" {
+			t.Fatalf("Expected the schema keyed first, got %v %v", schema, err)
+		}
+	}
+
+	set.Set["XYZ"] = StandardSchema{Category: "XYZ", Version: "D"}
+	if schema, _ := findSchema([]MessageSet{set}, "XYZ"); schema.Version != "D" {
+		t.Errorf("Expected the schema keyed by its category, got %v", schema)
+	}
+	if schema, _ := selectSchema([]MessageSet{set}, "XYZ", "B", ""); schema.Version != "B" {
+		t.Errorf("Expected version B, got %v", schema)
+	}
+}
+
+func Test_ScanField(t *testing.T) {
+	tests := []struct {
+		message string
+		name    string
+		value   string
+		found   bool
+	}{
+		{message: "-TITLE SAM -FAC EGLLZPZX -FACX 1", name: "FAC", value: "EGLLZPZX", found: true},
+		{message: "-TITLE SAM -FACX 1 -FAC  EGLLZPZX", name: "FAC", value: "EGLLZPZX", found: true},
+		{message: "-TITLE SAM -FACX 1", name: "FAC"},
+		{message: "-TITLE SAM -MARKER -ARCID X", name: "MARKER", found: true},
+		{message: "-TITLE SAM -MARKER", name: "MARKER", found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			value, found := scanField(tt.message, tt.name)
+			if value != tt.value || found != tt.found {
+				t.Errorf("Expected %q %v, got %q %v", tt.value, tt.found, value, found)
+			}
+		})
+	}
+}
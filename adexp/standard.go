@@ -0,0 +1,92 @@
+package adexp
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// standardSchemas holds the EUROCONTROL ADEXP schemas, one JSON file per
+// title, and the catalogue of the fields they reference
+//
+//go:embed standard/*.json
+var standardSchemas embed.FS
+
+// StandardName is the name of the message set returned by StandardMessageSet
+const StandardName = "standard"
+
+// standardSet is the standard message set, loaded on first use
+var (
+	standardOnce sync.Once
+	standardSet  *MessageSet
+)
+
+// StandardMessageSet returns the built-in schemas of the IFPS messages (IFPL,
+// CHG, CNL, DLA, DEP, ARR, RQP, RQS, ACH, APL, ACK, REJ, MAN) and the ETFMS
+// messages (SAM, SRM, SLC, SIP, SPA, SRJ, SWM, FLS, DES, ERR, RRP, RRN),
+// a basic flight data (BFD) schema for custom BFDs to extend, and the
+// catalogue of the primary and constructed fields. The embedded
+// files are only loaded once, every call returns a new copy that may be
+// modified freely.
+func StandardMessageSet() *MessageSet {
+	standardOnce.Do(func() {
+		standardSet = loadStandardMessageSet()
+	})
+	return standardSet.copy()
+}
+
+// loadStandardMessageSet loads the standard message set from the embedded files
+func loadStandardMessageSet() *MessageSet {
+	files, err := fs.Sub(standardSchemas, "standard")
+	if err != nil {
+		panic(err)
+	}
+	l := newLoader(StandardName)
+	if err := l.addFS(files); err != nil {
+		panic(err)
+	}
+	set, _, err := l.finish(nil)
+	if err != nil {
+		panic(fmt.Sprintf("adexp: invalid standard message set: %v", err))
+	}
+	return set
+}
+
+// copy returns a deep copy of the message set
+func (s *MessageSet) copy() *MessageSet {
+	c := &MessageSet{Name: s.Name, Set: make(map[string]StandardSchema, len(s.Set))}
+	for key, schema := range s.Set {
+		schema.Items = copyFields(schema.Items)
+		c.Set[key] = schema
+	}
+	if s.Catalogue != nil {
+		c.Catalogue = make(map[string]DataField, len(s.Catalogue))
+		for name, field := range s.Catalogue {
+			field.Subfields = copyFields(field.Subfields)
+			c.Catalogue[name] = field
+		}
+	}
+	return c
+}
+
+// copyFields returns a deep copy of fields
+func copyFields(fields []DataField) []DataField {
+	if fields == nil {
+		return nil
+	}
+	c := make([]DataField, len(fields))
+	for i, field := range fields {
+		field.Subfields = copyFields(field.Subfields)
+		c[i] = field
+	}
+	return c
+}
+
+// WithStandard returns the message sets followed by the standard message set.
+// Sets are searched in order, so schemas in custom override the standard
+// schema of the same title.
+func WithStandard(custom ...MessageSet) []MessageSet {
+	sets := append([]MessageSet{}, custom...)
+	return append(sets, *StandardMessageSet())
+}
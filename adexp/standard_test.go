@@ -0,0 +1,97 @@
+package adexp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_StandardMessageSet(t *testing.T) {
+	set := StandardMessageSet()
+	if set.Name != StandardName {
+		t.Errorf("Expected name %s, got %s", StandardName, set.Name)
+	}
+	for _, title := range []string{
+		"IFPL", "CHG", "CNL", "DLA", "DEP", "ARR", "RQP", "RQS", "ACH", "APL", "ACK", "REJ", "MAN",
+		"SAM", "SRM", "SLC", "SIP", "SPA", "SRJ", "SWM", "FLS", "DES", "ERR", "RRP", "RRN", "BFD",
+	} {
+		schema, ok := set.Set[title]
+		if !ok {
+			t.Errorf("Missing standard schema for %s", title)
+			continue
+		}
+		if len(schema.Items) == 0 || schema.Items[0].DataItem != "TITLE" {
+			t.Errorf("Expected schema %s to start with TITLE", title)
+		}
+	}
+
+	// The embedded files are free of FRN inconsistencies
+	l := newLoader(StandardName)
+	files, _ := standardSchemas.ReadDir("standard")
+	for _, file := range files {
+		content, _ := standardSchemas.ReadFile("standard/" + file.Name())
+		l.addJSON(file.Name(), content)
+	}
+	if _, warnings, err := l.finish(nil); err != nil || len(warnings) != 0 {
+		t.Errorf("Expected no errors or warnings, got %v %v", err, warnings)
+	}
+
+	// Every call returns its own copy
+	set.Set["IFPL"].Items[0].DataItem = "XXX"
+	set.Catalogue["REFDATA"].Subfields[0].DataItem = "XXX"
+	delete(set.Set, "SAM")
+	fresh := StandardMessageSet()
+	if _, ok := fresh.Set["SAM"]; !ok || fresh.Set["IFPL"].Items[0].DataItem != "TITLE" || fresh.Catalogue["REFDATA"].Subfields[0].DataItem != "SENDER" {
+		t.Errorf("Expected modifications not to affect later calls")
+	}
+}
+
+func Test_StandardMessageSet_Parse(t *testing.T) {
+	parser := NewParser([]MessageSet{*StandardMessageSet()})
+
+	for _, filename := range []string{"SAM.txt", "SRM.txt", "SLC.txt", "FLS.txt", "DES.txt"} {
+		t.Run(filename, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("../test/fpl/adexp", filename))
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+			fp, warnings, err := parser.ParseWithWarnings(string(content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(warnings) != 0 {
+				t.Errorf("Unexpected warnings: %v", warnings)
+			}
+			if fp["ARCID"] != "AMC101" || fp["ADEP"] != LocationIndicator("EGLL") {
+				t.Errorf("Unexpected flight plan: %v", fp)
+			}
+		})
+	}
+}
+
+func Test_WithStandard(t *testing.T) {
+	custom := MessageSet{Name: "custom", Set: map[string]StandardSchema{
+		"SLC": {Category: "SLC", Items: []DataField{
+			{FRN: 1, DataItem: "TITLE", Mendatory: true},
+			{FRN: 2, DataItem: "ARCID", Mendatory: true},
+			{FRN: 3, DataItem: "CANCELLED"},
+		}},
+	}}
+	parser := NewParser(WithStandard(custom))
+
+	fp, warnings, err := parser.ParseWithWarnings("-TITLE SLC -ARCID AMC101 -CANCELLED YES -REASON VOID")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fp["CANCELLED"] != "YES" || len(warnings) != 1 {
+		t.Errorf("Expected the custom SLC schema to be used, got %v with warnings %v", fp, warnings)
+	}
+
+	fp, err = parser.Parse("-TITLE SAM -ARCID AMC101 -CTOT 1200")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fp["CTOT"] != (Time{Hour: 12}) {
+		t.Errorf("Expected the standard SAM schema to be used, got %v", fp)
+	}
+}
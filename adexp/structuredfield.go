@@ -5,10 +5,13 @@ import (
 )
 
 // parseStructuredField parses a structured field and returns its key, value (as a map), and any error
-func (p *Parser) parseStructuredField(field DataField) (string, map[string]interface{}, error) {
+func (p *parseState) parseStructuredField(field DataField) (string, map[string]interface{}, error) {
 	structuredData := make(map[string]interface{})
+	p.pushField(field.DataItem)
+	defer p.popField()
 
 	for {
+		subFieldStart := p.currentPos
 		subFieldName, subFieldValue, err := p.parseSubField(field.Subfields)
 		if err != nil {
 			return "", nil, err
@@ -16,14 +19,14 @@ func (p *Parser) parseStructuredField(field DataField) (string, map[string]inter
 		if subFieldName == "" {
 			break
 		}
-		structuredData[subFieldName] = subFieldValue
+		p.setField(structuredData, subFieldStart, p.findField(subFieldName, field.Subfields), subFieldValue)
 	}
 
 	return field.DataItem, structuredData, nil
 }
 
 // parseSubField parses a subfield and returns its name, value, and any error
-func (p *Parser) parseSubField(subfields []DataField) (string, interface{}, error) {
+func (p *parseState) parseSubField(subfields []DataField) (string, interface{}, error) {
 	p.buffer.Reset()
 	for p.currentPos < len(p.message) && p.message[p.currentPos] == ' ' {
 		p.currentPos++
@@ -31,6 +34,7 @@ func (p *Parser) parseSubField(subfields []DataField) (string, interface{}, erro
 	if p.currentPos >= len(p.message) || p.message[p.currentPos] != '-' {
 		return "", nil, nil // End of structured field
 	}
+	subFieldStart := p.currentPos
 	p.currentPos++ // Skip the '-'
 
 	for p.currentPos < len(p.message) && p.message[p.currentPos] != ' ' {
@@ -39,10 +43,14 @@ func (p *Parser) parseSubField(subfields []DataField) (string, interface{}, erro
 	}
 	subFieldName := p.buffer.String()
 
+	if subFieldName == "BEGIN" {
+		return p.parseSubList(subfields, subFieldStart)
+	}
+
 	subFieldDef := p.findField(subFieldName, subfields)
 	if subFieldDef == nil {
 		// This might be a new top-level field, so we need to backtrack
-		p.currentPos -= len(subFieldName) + 2 // +2 for '-' and space
+		p.currentPos = subFieldStart
 		return "", nil, nil
 	}
 
@@ -54,6 +62,39 @@ func (p *Parser) parseSubField(subfields []DataField) (string, interface{}, erro
 		_, value, err := p.parseStructuredField(*subFieldDef)
 		return subFieldName, value, err
 	default:
-		return "", nil, fmt.Errorf("unsupported subfield type for '%s'", subFieldName)
+		err := p.errorAt(subFieldStart, CodeInvalidField, fmt.Errorf("unsupported subfield type for '%s'", subFieldName))
+		if p.Opts.Mode != ModeLenient {
+			return "", nil, err
+		}
+		p.warnings = append(p.warnings, err)
+		p.skipUnknownField()
+		return p.parseSubField(subfields)
+	}
+}
+
+// parseSubList parses a -BEGIN/-END list nested in a structured field or list
+// item. Lists not defined in subfields belong to an enclosing field, so the
+// position is reset to start.
+func (p *parseState) parseSubList(subfields []DataField, start int) (string, interface{}, error) {
+	p.buffer.Reset()
+	if p.currentPos < len(p.message) {
+		p.currentPos++ // Skip the space after BEGIN
+	}
+	for p.currentPos < len(p.message) && p.message[p.currentPos] != ' ' && p.message[p.currentPos] != '-' {
+		p.buffer.WriteByte(p.message[p.currentPos])
+		p.currentPos++
+	}
+	listFieldName := p.buffer.String()
+
+	field := p.findField(listFieldName, subfields)
+	if field == nil || field.Type != ListField {
+		p.currentPos = start
+		return "", nil, nil
+	}
+
+	_, value, err := p.parseListField(*field)
+	if err != nil {
+		return "", nil, err
 	}
+	return listFieldName, value, nil
 }
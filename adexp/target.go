@@ -0,0 +1,171 @@
+package adexp
+
+import "strings"
+
+// applyTargets moves the values of fields declaring a Target from data to the
+// Target path in root. Subfields of list items and of the occurrences of
+// repeatable fields are mapped within the item.
+func applyTargets(fields []DataField, data, root map[string]interface{}) {
+	for _, field := range fields {
+		value, ok := data[field.DataItem]
+		if !ok {
+			continue
+		}
+
+		switch field.Type {
+		case StructuredField:
+			if m, ok := value.(map[string]interface{}); ok {
+				applyTargets(field.Subfields, m, root)
+				if len(m) == 0 {
+					// Every subfield was moved elsewhere
+					delete(data, field.DataItem)
+					continue
+				}
+			}
+			if occurrences, ok := value.([]interface{}); ok && field.Repeatable {
+				for _, occurrence := range occurrences {
+					if m, ok := occurrence.(map[string]interface{}); ok {
+						applyTargets(field.Subfields, m, m)
+					}
+				}
+			}
+		case ListField:
+			items, _ := value.([]interface{})
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					applyListItemTargets(field.Subfields, m)
+				}
+			}
+		}
+
+		if field.Target != "" {
+			delete(data, field.DataItem)
+			setPath(root, field.Target, value)
+		}
+	}
+}
+
+// applyListItemTargets maps the subfields of a list item, which holds either
+// its structured subfields or, with FlattenListItems, their content
+func applyListItemTargets(subfields []DataField, item map[string]interface{}) {
+	applyTargets(subfields, item, item)
+	for _, subfield := range subfields {
+		if _, ok := item[subfield.DataItem]; !ok && subfield.Type == StructuredField {
+			applyTargets(subfield.Subfields, item, item)
+		}
+	}
+}
+
+// restoreTargets is the inverse of applyTargets: it returns a copy of data in
+// which the values found at the Target path in root are put back under their
+// field. Maps below data are copied only where a value is restored.
+func restoreTargets(fields []DataField, data, root map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		out[k] = v
+	}
+
+	for _, field := range fields {
+		if field.Target != "" {
+			if value, ok := getPath(root, field.Target); ok {
+				out[field.DataItem] = value
+			}
+		}
+
+		value, ok := out[field.DataItem]
+		switch field.Type {
+		case StructuredField:
+			if occurrences, isList := value.([]interface{}); isList && field.Repeatable {
+				restored := make([]interface{}, len(occurrences))
+				for i, occurrence := range occurrences {
+					restored[i] = occurrence
+					if m, ok := occurrence.(map[string]interface{}); ok {
+						restored[i] = restoreTargets(field.Subfields, m, m)
+					}
+				}
+				out[field.DataItem] = restored
+				continue
+			}
+			m, isMap := value.(map[string]interface{})
+			if (ok && !isMap) || !hasTarget(field.Subfields) {
+				continue
+			}
+			if m = restoreTargets(field.Subfields, m, root); len(m) > 0 {
+				out[field.DataItem] = m
+			}
+		case ListField:
+			items, isList := value.([]interface{})
+			if !isList || !hasTarget(field.Subfields) {
+				continue
+			}
+			restored := make([]interface{}, len(items))
+			for i, item := range items {
+				restored[i] = item
+				if m, ok := item.(map[string]interface{}); ok {
+					restored[i] = restoreListItemTargets(field.Subfields, m)
+				}
+			}
+			out[field.DataItem] = restored
+		}
+	}
+	return out
+}
+
+// restoreListItemTargets restores the subfields of a list item. Items holding
+// none of their structured subfields are restored in the flattened form.
+func restoreListItemTargets(subfields []DataField, item map[string]interface{}) map[string]interface{} {
+	flattened := make([]DataField, 0, len(subfields))
+	for _, subfield := range subfields {
+		if subfield.Type != StructuredField {
+			flattened = append(flattened, subfield)
+			continue
+		}
+		if _, ok := item[subfield.DataItem]; ok {
+			return restoreTargets(subfields, item, item)
+		}
+		flattened = append(flattened, subfield.Subfields...)
+	}
+	return restoreTargets(flattened, item, item)
+}
+
+// hasTarget reports whether any of fields or their structured subfields declares a Target
+func hasTarget(fields []DataField) bool {
+	for _, field := range fields {
+		if field.Target != "" {
+			return true
+		}
+		if field.Type == StructuredField && hasTarget(field.Subfields) {
+			return true
+		}
+	}
+	return false
+}
+
+// getPath returns the value at the dotted path in data
+func getPath(data map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		m, ok := data[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		data = m
+	}
+	value, ok := data[keys[len(keys)-1]]
+	return value, ok
+}
+
+// setPath stores value at the dotted path in data, creating the maps on the
+// way. Values in the way that are not maps are replaced.
+func setPath(data map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		m, ok := data[key].(map[string]interface{})
+		if !ok {
+			m = make(map[string]interface{})
+			data[key] = m
+		}
+		data = m
+	}
+	data[keys[len(keys)-1]] = value
+}
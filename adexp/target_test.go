@@ -0,0 +1,135 @@
+package adexp
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Parse_Target(t *testing.T) {
+	content, err := os.ReadFile("../test/fpl/adexp/OFP.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     ParserOpts
+		expected map[string]interface{}
+	}{
+		{
+			name: "items keyed by subfield",
+			expected: map[string]interface{}{
+				"TITLE":   "OFP",
+				"REFDATA": map[string]interface{}{"SENDER": map[string]interface{}{"FAC": "EDDFDLHX"}},
+				"ARCID":   "DLH4AB",
+				"ADEP":    LocationIndicator("EDDF"),
+				"ADES":    LocationIndicator("KJFK"),
+				"EOBT":    Time{Hour: 10, Minute: 30},
+				"RTEPTS": []interface{}{
+					map[string]interface{}{"PT": map[string]interface{}{
+						"PTID": "TOBAK", "TO": Time{Hour: 10, Minute: 52}, "FL": FlightLevel{Unit: "F", Value: 350},
+					}},
+					map[string]interface{}{"PT": map[string]interface{}{
+						"PTID": "MALOT", "TO": Time{Hour: 11, Minute: 48}, "FL": FlightLevel{Unit: "F", Value: 370},
+					}},
+				},
+				"PAX": "180",
+			},
+		},
+		{
+			name: "flattened items",
+			opts: ParserOpts{FlattenListItems: true},
+			expected: map[string]interface{}{
+				"TITLE":   "OFP",
+				"REFDATA": map[string]interface{}{"SENDER": map[string]interface{}{"FAC": "EDDFDLHX"}},
+				"ARCID":   "DLH4AB",
+				"ADEP":    LocationIndicator("EDDF"),
+				"ADES":    LocationIndicator("KJFK"),
+				"EOBT":    Time{Hour: 10, Minute: 30},
+				"RTEPTS": []interface{}{
+					map[string]interface{}{"PT": map[string]interface{}{
+						"PTID": "TOBAK", "TO": Time{Hour: 10, Minute: 52}, "FL": FlightLevel{Unit: "F", Value: 350},
+					}},
+					map[string]interface{}{"PT": map[string]interface{}{
+						"PTID": "MALOT", "TO": Time{Hour: 11, Minute: 48}, "FL": FlightLevel{Unit: "F", Value: 370},
+					}},
+				},
+				"PAX": "180",
+			},
+		},
+		{
+			name: "targets ignored",
+			opts: ParserOpts{IgnoreTargets: true},
+			expected: map[string]interface{}{
+				"TITLE":    "OFP",
+				"ORIGIN":   map[string]interface{}{"FAC": "EDDFDLHX"},
+				"CALLSIGN": "DLH4AB",
+				"DEP":      LocationIndicator("EDDF"),
+				"DEST":     LocationIndicator("KJFK"),
+				"OBT":      Time{Hour: 10, Minute: 30},
+				"WPTS": []interface{}{
+					map[string]interface{}{"WPT": map[string]interface{}{
+						"NAME": "TOBAK", "ETO": Time{Hour: 10, Minute: 52}, "LVL": FlightLevel{Unit: "F", Value: 350},
+					}},
+					map[string]interface{}{"WPT": map[string]interface{}{
+						"NAME": "MALOT", "ETO": Time{Hour: 11, Minute: 48}, "LVL": FlightLevel{Unit: "F", Value: 370},
+					}},
+				},
+				"PAX": "180",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParserWithOpts([]MessageSet{LoadTestMessageSet(t)}, tt.opts)
+			fp, err := parser.Parse(string(content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if !reflect.DeepEqual(fp, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, fp)
+			}
+		})
+	}
+}
+
+func Test_Marshal_Target(t *testing.T) {
+	schema, err := findSchema([]MessageSet{LoadTestMessageSet(t)}, "OFP")
+	if err != nil {
+		t.Fatalf("Failed to find schema: %v", err)
+	}
+
+	fp := map[string]interface{}{
+		"TITLE":   "OFP",
+		"REFDATA": map[string]interface{}{"SENDER": map[string]interface{}{"FAC": "EDDFDLHX"}},
+		"ARCID":   "DLH4AB",
+		"RTEPTS": []interface{}{
+			map[string]interface{}{"PT": map[string]interface{}{"PTID": "TOBAK", "FL": "F350"}},
+			map[string]interface{}{"PT": map[string]interface{}{"PTID": "MALOT", "TO": "1148"}},
+		},
+	}
+
+	s, err := Marshal(fp, *schema)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := strings.Join([]string{
+		"-TITLE OFP",
+		"-ORIGIN -FAC EDDFDLHX",
+		"-CALLSIGN DLH4AB",
+		"-BEGIN WPTS",
+		"-WPT -NAME TOBAK -LVL F350",
+		"-WPT -NAME MALOT -ETO 1148",
+		"-END WPTS",
+		"",
+	}, "\n")
+	if s != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, s)
+	}
+	if _, ok := fp["CALLSIGN"]; ok {
+		t.Errorf("Marshal must not modify the flight plan")
+	}
+}
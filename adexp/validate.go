@@ -0,0 +1,120 @@
+package adexp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MandatoryPolicy controls how missing mandatory fields are reported
+type MandatoryPolicy uint8
+
+const (
+	// MandatoryWarn reports missing mandatory fields as warnings of ParseWithWarnings
+	MandatoryWarn MandatoryPolicy = iota
+	// MandatoryFail makes Parse and ParseWithWarnings fail on missing mandatory fields
+	MandatoryFail
+	// MandatoryIgnore skips the check
+	MandatoryIgnore
+)
+
+// MissingFieldError reports a mandatory field that is not present in a message
+type MissingFieldError struct {
+	// Path is the dotted path of the field, e.g. REFDATA.SENDER.FAC. List
+	// items are indexed, e.g. RTEPTS[2].PT.PTID
+	Path string
+}
+
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrorMendatory, e.Path)
+}
+
+func (e *MissingFieldError) Unwrap() error {
+	return ErrorMendatory
+}
+
+// ValidateMandatory walks the schema, including the subfields of structured
+// and list fields, and returns a MissingFieldError for every mandatory field
+// missing in fp
+func ValidateMandatory(schema *StandardSchema, fp map[string]interface{}) []error {
+	return validateFields(schema.Items, fp, "")
+}
+
+func validateFields(fields []DataField, data map[string]interface{}, prefix string) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if seen[field.DataItem] {
+			continue
+		}
+		seen[field.DataItem] = true
+		path := prefix + field.DataItem
+
+		value, ok := data[field.DataItem]
+		if !ok {
+			if field.Mendatory {
+				errs = append(errs, &MissingFieldError{Path: path})
+			}
+			continue
+		}
+
+		switch field.Type {
+		case StructuredField:
+			if m, ok := value.(map[string]interface{}); ok {
+				errs = append(errs, validateFields(field.Subfields, m, path+".")...)
+			}
+			if occurrences, ok := value.([]interface{}); ok && field.Repeatable {
+				for i, occurrence := range occurrences {
+					if m, ok := occurrence.(map[string]interface{}); ok {
+						errs = append(errs, validateFields(field.Subfields, m, fmt.Sprintf("%s[%d].", path, i))...)
+					}
+				}
+			}
+		case ListField:
+			items, _ := value.([]interface{})
+			for i, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					errs = append(errs, validateListItem(field.Subfields, m, fmt.Sprintf("%s[%d].", path, i))...)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateListItem checks a structured list item. Items hold either the
+// subfield (e.g. PT) or, as returned with FlattenListItems, its content.
+func validateListItem(subfields []DataField, item map[string]interface{}, prefix string) []error {
+	var errs []error
+	for _, subfield := range subfields {
+		if subfield.Type != StructuredField {
+			errs = append(errs, validateFields([]DataField{subfield}, item, prefix)...)
+			continue
+		}
+		data := item
+		if m, ok := item[subfield.DataItem].(map[string]interface{}); ok {
+			data = m
+		} else if !containsSubfield(item, subfield.Subfields) {
+			if subfield.Mendatory {
+				errs = append(errs, &MissingFieldError{Path: prefix + subfield.DataItem})
+			}
+			continue
+		}
+		errs = append(errs, validateFields(subfield.Subfields, data, prefix+subfield.DataItem+".")...)
+	}
+	return errs
+}
+
+// checkMandatory applies the MandatoryPolicy of the parser to a parsed message
+func (p *parseState) checkMandatory(fp map[string]interface{}) ([]error, error) {
+	if p.Opts.Mandatory == MandatoryIgnore || p.currentSchema == nil {
+		return nil, nil
+	}
+	errs := ValidateMandatory(p.currentSchema, fp)
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	if p.Opts.Mandatory == MandatoryFail {
+		return nil, errors.Join(errs...)
+	}
+	return errs, nil
+}
@@ -0,0 +1,111 @@
+package adexp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_ParseWithWarnings_Mandatory(t *testing.T) {
+	testSchema := []MessageSet{LoadTestMessageSet(t)}
+	message := "-TITLE BFD -REFDATA -SENDER -RECVR -FAC EBSZZXZQ -SEQNUM 006 -ADEP EDDW -ADES GMME"
+	expected := []string{"REFDATA.SENDER.FAC", "ARCID"}
+
+	testCases := []struct {
+		name     string
+		policy   MandatoryPolicy
+		warnings int
+		fail     bool
+	}{
+		{name: "warn", policy: MandatoryWarn, warnings: len(expected)},
+		{name: "fail", policy: MandatoryFail, fail: true},
+		{name: "ignore", policy: MandatoryIgnore},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewParserWithOpts(testSchema, ParserOpts{Mandatory: tc.policy})
+			fp, warnings, err := parser.ParseWithWarnings(message)
+			if tc.fail {
+				if !errors.Is(err, ErrorMendatory) {
+					t.Fatalf("Expected %v, got %v", ErrorMendatory, err)
+				}
+				for _, path := range expected {
+					if !containsPath(err, path) {
+						t.Errorf("Expected error for %s in %v", path, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWithWarnings failed: %v", err)
+			}
+			if fp["ADEP"] != "EDDW" {
+				t.Errorf("Expected ADEP to be EDDW, got %v", fp["ADEP"])
+			}
+			if len(warnings) != tc.warnings {
+				t.Fatalf("Expected %d warnings, got %v", tc.warnings, warnings)
+			}
+			for _, path := range expected[:tc.warnings] {
+				if !containsPath(errors.Join(warnings...), path) {
+					t.Errorf("Expected warning for %s in %v", path, warnings)
+				}
+			}
+		})
+	}
+
+	// Parse keeps ignoring warnings
+	if _, err := NewParser(testSchema).Parse(message); err != nil {
+		t.Errorf("Parse failed: %v", err)
+	}
+}
+
+func Test_ValidateMandatory(t *testing.T) {
+	schema := &StandardSchema{
+		Category: "TST",
+		Items: []DataField{
+			{DataItem: "TITLE", Type: Basicfield, Mendatory: true},
+			{DataItem: "RTEPTS", Type: ListField, Subfields: []DataField{
+				{DataItem: "PT", Type: StructuredField, Mendatory: true, Subfields: []DataField{
+					{DataItem: "PTID", Type: Basicfield, Mendatory: true},
+					{DataItem: "FL", Type: Basicfield},
+				}},
+			}},
+		},
+	}
+	fp := map[string]interface{}{
+		"TITLE": "TST",
+		"RTEPTS": []interface{}{
+			map[string]interface{}{"PTID": "WOODY", "FL": "F210"},
+			map[string]interface{}{"FL": "F290"},
+			map[string]interface{}{"PT": map[string]interface{}{"FL": "F330"}},
+		},
+	}
+
+	var paths []string
+	for _, err := range ValidateMandatory(schema, fp) {
+		var missing *MissingFieldError
+		if !errors.As(err, &missing) {
+			t.Fatalf("Expected a MissingFieldError, got %T", err)
+		}
+		paths = append(paths, missing.Path)
+	}
+	expected := []string{"RTEPTS[1].PT.PTID", "RTEPTS[2].PT.PTID"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func containsPath(err error, path string) bool {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return false
+	}
+	for _, e := range joined.Unwrap() {
+		var missing *MissingFieldError
+		if errors.As(e, &missing) && missing.Path == path {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package goflightplan
 
-func ParseAFTNHeader() error {
+import "github.com/davidkohl/goflightplan/aftn"
 
-	return nil
+// ParseAFTNHeader splits an AFTN message into its envelope and the message text
+func ParseAFTNHeader(s string) (*aftn.Envelope, string, error) {
+	return aftn.Parse(s)
 }
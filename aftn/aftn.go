@@ -0,0 +1,142 @@
+package aftn
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Priority is the AFTN priority indicator
+type Priority string
+
+const (
+	PrioritySS Priority = "SS" // distress
+	PriorityDD Priority = "DD" // urgency
+	PriorityFF Priority = "FF" // flight safety
+	PriorityGG Priority = "GG" // meteorological, flight regularity, aeronautical information
+	PriorityKK Priority = "KK" // aeronautical administrative
+)
+
+const (
+	StartOfMessage = "ZCZC"
+	EndOfMessage   = "NNNN"
+)
+
+// Envelope holds the AFTN heading, address and origin of a message
+type Envelope struct {
+	// Channel and Sequence form the transmission identification, e.g. ABC 123
+	Channel  string
+	Sequence string
+	// AdditionalServices is the rest of the start-of-message line, usually the
+	// time of transmission
+	AdditionalServices string
+	Priority           Priority
+	Addressees         []string
+	// FilingTime is the filing date and time as DDHHMM
+	FilingTime string
+	Originator string
+	// OptionalHeading is the optional heading information following the originator
+	OptionalHeading string
+}
+
+var ErrorNoStartOfMessage = errors.New("start of message ZCZC not found")
+var ErrorNoEndOfMessage = errors.New("end of message NNNN not found")
+var ErrorInvalidHeading = errors.New("invalid heading line")
+var ErrorInvalidAddress = errors.New("invalid address")
+var ErrorInvalidOrigin = errors.New("invalid origin line")
+
+var (
+	transmissionID = regexp.MustCompile(`^([A-Z]{3,4})([0-9]{3,4})$`)
+	addressee      = regexp.MustCompile(`^[A-Z]{8}$`)
+	filingTime     = regexp.MustCompile(`^(0[1-9]|[12][0-9]|3[01])([01][0-9]|2[0-3])[0-5][0-9]$`)
+	priorities     = map[Priority]bool{PrioritySS: true, PriorityDD: true, PriorityFF: true, PriorityGG: true, PriorityKK: true}
+)
+
+// Parse splits an AFTN message into its envelope and the message text between
+// the origin line and NNNN. Anything before ZCZC is ignored.
+func Parse(s string) (*Envelope, string, error) {
+	start := strings.Index(s, StartOfMessage)
+	if start == -1 {
+		return nil, "", ErrorNoStartOfMessage
+	}
+	s = s[start+len(StartOfMessage):]
+	end := strings.Index(s, EndOfMessage)
+	if end == -1 {
+		return nil, "", ErrorNoEndOfMessage
+	}
+	lines := splitLines(s[:end])
+
+	env := &Envelope{}
+
+	// Heading: transmission identification and additional services
+	if len(lines) == 0 {
+		return nil, "", ErrorInvalidHeading
+	}
+	heading := strings.Fields(lines[0])
+	if len(heading) == 0 {
+		return nil, "", ErrorInvalidHeading
+	}
+	m := transmissionID.FindStringSubmatch(heading[0])
+	if m == nil {
+		return nil, "", fmt.Errorf("transmission identification '%s': %w", heading[0], ErrorInvalidHeading)
+	}
+	env.Channel = m[1]
+	env.Sequence = m[2]
+	env.AdditionalServices = strings.Join(heading[1:], " ")
+
+	// Address: priority indicator followed by addressees, possibly over
+	// several lines, up to the origin line
+	i := 1
+	for ; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		if len(fields) == 0 {
+			continue
+		}
+		if env.Priority == "" {
+			env.Priority = Priority(fields[0])
+			if !priorities[env.Priority] {
+				return nil, "", fmt.Errorf("priority indicator '%s': %w", fields[0], ErrorInvalidAddress)
+			}
+			fields = fields[1:]
+		} else if filingTime.MatchString(fields[0]) {
+			break
+		}
+		for _, field := range fields {
+			if !addressee.MatchString(field) {
+				return nil, "", fmt.Errorf("addressee '%s': %w", field, ErrorInvalidAddress)
+			}
+			env.Addressees = append(env.Addressees, field)
+		}
+	}
+	if len(env.Addressees) == 0 {
+		return nil, "", fmt.Errorf("no addressee: %w", ErrorInvalidAddress)
+	}
+
+	// Origin: filing time, originator and optional heading information
+	if i >= len(lines) {
+		return nil, "", ErrorInvalidOrigin
+	}
+	origin := strings.Fields(lines[i])
+	if len(origin) < 2 || !addressee.MatchString(origin[1]) {
+		return nil, "", fmt.Errorf("'%s': %w", lines[i], ErrorInvalidOrigin)
+	}
+	env.FilingTime = origin[0]
+	env.Originator = origin[1]
+	env.OptionalHeading = strings.Join(origin[2:], " ")
+
+	text := strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+	return env, text, nil
+}
+
+// splitLines splits s into lines, dropping carriage returns and the empty
+// lines produced by line feed padding
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r", ""), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,77 @@
+package aftn
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Parse(t *testing.T) {
+	content, err := os.ReadFile("../test/fpl/aftn/FPL.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	env, text, err := Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := &Envelope{
+		Channel:            "LPA",
+		Sequence:           "042",
+		AdditionalServices: "151230",
+		Priority:           PriorityFF,
+		Addressees:         []string{"LFPGZPZX", "LFFFZQZX", "LECMZQZX", "EUCHZMFP"},
+		FilingTime:         "151229",
+		Originator:         "LPPRZPZX",
+		OptionalHeading:    "REF 0815",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected envelope %+v, got %+v", expected, env)
+	}
+	if !strings.HasPrefix(text, "(FPL-ABC123-IN\n") || !strings.HasSuffix(text, "REG/DESEL)") {
+		t.Errorf("Unexpected message text: %q", text)
+	}
+}
+
+func Test_Parse_Minimal(t *testing.T) {
+	env, text, err := Parse("garbage\r\nZCZC ABC0001\r\nGG EDDFZQZX\r\n010000 EDDMZQZX\r\n(CNL-WMT912-EDJA2010-LIRF-0)\r\nNNNN")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if env.Channel != "ABC" || env.Sequence != "0001" || env.AdditionalServices != "" || env.Priority != PriorityGG {
+		t.Errorf("Unexpected heading: %+v", env)
+	}
+	if text != "(CNL-WMT912-EDJA2010-LIRF-0)" {
+		t.Errorf("Unexpected message text: %q", text)
+	}
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected error
+	}{
+		{name: "no start", message: "GG EDDFZQZX\n010000 EDDMZQZX\nTEXT\nNNNN", expected: ErrorNoStartOfMessage},
+		{name: "no end", message: "ZCZC ABC001\nGG EDDFZQZX\n010000 EDDMZQZX\nTEXT", expected: ErrorNoEndOfMessage},
+		{name: "invalid transmission id", message: "ZCZC 123ABC\nGG EDDFZQZX\n010000 EDDMZQZX\nTEXT\nNNNN", expected: ErrorInvalidHeading},
+		{name: "invalid priority", message: "ZCZC ABC001\nXX EDDFZQZX\n010000 EDDMZQZX\nTEXT\nNNNN", expected: ErrorInvalidAddress},
+		{name: "invalid addressee", message: "ZCZC ABC001\nGG EDDF\n010000 EDDMZQZX\nTEXT\nNNNN", expected: ErrorInvalidAddress},
+		{name: "no addressee", message: "ZCZC ABC001\nGG\n010000 EDDMZQZX\nTEXT\nNNNN", expected: ErrorInvalidAddress},
+		{name: "no origin", message: "ZCZC ABC001\nGG EDDFZQZX\nNNNN", expected: ErrorInvalidOrigin},
+		{name: "invalid originator", message: "ZCZC ABC001\nGG EDDFZQZX\n010000 EDDM\nTEXT\nNNNN", expected: ErrorInvalidOrigin},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := Parse(tc.message)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
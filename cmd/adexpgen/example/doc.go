@@ -0,0 +1,5 @@
+// Package example holds the code adexpgen generates for the BFD, TFD and OFP
+// schemas of the test message set.
+package example
+
+//go:generate go run .. -dir ../../../test/schema -types BFD,TFD,OFP -o messages_gen.go
@@ -0,0 +1,77 @@
+package example
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/davidkohl/goflightplan/adexp"
+)
+
+func Test_OFP(t *testing.T) {
+	content, err := os.ReadFile("../../../test/fpl/adexp/OFP.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	var ofp OFP
+	if err := ofp.Unmarshal(string(content)); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if ofp.CALLSIGN != "DLH4AB" || ofp.ORIGIN == nil || ofp.ORIGIN.FAC != "EDDFDLHX" {
+		t.Errorf("Unexpected message: %+v", ofp)
+	}
+	if ofp.DEP == nil || *ofp.DEP != "EDDF" || ofp.OBT == nil || *ofp.OBT != (adexp.Time{Hour: 10, Minute: 30}) {
+		t.Errorf("Expected typed DEP and OBT, got %v %v", ofp.DEP, ofp.OBT)
+	}
+	if len(ofp.WPTS) != 2 || ofp.WPTS[1].WPT == nil || ofp.WPTS[1].WPT.NAME != "MALOT" || *ofp.WPTS[1].WPT.LVL != (adexp.FlightLevel{Unit: "F", Value: 370}) {
+		t.Errorf("Unexpected waypoints: %+v", ofp.WPTS)
+	}
+
+	s, err := ofp.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if s != string(content) {
+		t.Errorf("Expected:\n%s\ngot:\n%s", content, s)
+	}
+}
+
+func Test_RoundTrip(t *testing.T) {
+	tests := []struct {
+		file    string
+		message interface {
+			Unmarshal(string) error
+			Marshal() (string, error)
+		}
+		decoded interface {
+			Unmarshal(string) error
+		}
+	}{
+		{file: "BFD.txt", message: &BFD{}, decoded: &BFD{}},
+		{file: "TFD.txt", message: &TFD{}, decoded: &TFD{}},
+		{file: "OFP.txt", message: &OFP{}, decoded: &OFP{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			content, err := os.ReadFile("../../../test/fpl/adexp/" + tt.file)
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+			if err := tt.message.Unmarshal(string(content)); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			s, err := tt.message.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if err := tt.decoded.Unmarshal(s); err != nil {
+				t.Fatalf("Unmarshal of the marshalled message failed: %v", err)
+			}
+			if !reflect.DeepEqual(tt.message, tt.decoded) {
+				t.Errorf("Expected %+v, got %+v", tt.message, tt.decoded)
+			}
+		})
+	}
+}
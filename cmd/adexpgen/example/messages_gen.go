@@ -0,0 +1,919 @@
+// Code generated by adexpgen from the message set custom; DO NOT EDIT.
+
+package example
+
+import "github.com/davidkohl/goflightplan/adexp"
+
+// BFD is a message of the ADEXP title BFD version 0.1
+type BFD struct {
+	// Title of the ADEXP message
+	TITLE string
+	// Message reference with sender, receiver and sequence number
+	REFDATA *BFD_REFDATA
+	// Aircraft identification
+	ARCID string
+	// SSR code
+	SSRCODE string
+	// Aerodrom of departure
+	ADEP string
+	// Aerodrom of destination
+	ADES string
+	// Aircraft type
+	ARCTYP string
+	// Number of aircraft
+	NBARC string
+	// Wake turbulence category
+	WKTRC string
+	// Flight rules
+	FLTRUL string
+	// Type of flight
+	FLTTYP string
+	// IFPS flight plan identifier
+	IFPLID string
+	// Day of Flight
+	EOBD string
+	// Estimated off block time
+	EOBT string
+	// Estimated landing time, YYMMDDHHMM
+	ELDT string
+	// Flight plan category
+	FPLCAT string
+	// CTOT
+	CTOT string
+	// Standard instrument departure
+	SID string
+	// Standard arrival route
+	STAR string
+	// Aircraft registration
+	REG string
+	// Complete ICAO field 15
+	ROUTE string
+	// Route points
+	RTEPTS []BFD_RTEPTS
+	// Cleard flight level
+	CFL *BFD_CFL
+	// Requested flight level
+	RFL string
+	// Speed
+	SPEED string
+	// Total estimated elapsed time
+	TTLEET string
+	// Coordination data
+	COORDATA *BFD_COORDATA
+	// Communication, navigation and approach equipment
+	CEQPT string
+	// Surveillance equipment
+	SEQPT string
+	// Equipment capabilities and status
+	EQCST []string
+	// Aircraft operator
+	OPR string
+	// First alternate aerodrome
+	ALTRNT1 *adexp.LocationIndicator
+	// Second alternate aerodrome
+	ALTRNT2 *adexp.LocationIndicator
+	// Remarks
+	RMK string
+}
+
+// Unmarshal parses the BFD message into m
+func (m *BFD) Unmarshal(message string) error {
+	fp, err := bfdParser.Parse(message)
+	if err != nil {
+		return err
+	}
+	*m = BFD{}
+	m.fromMap(fp)
+	return nil
+}
+
+// Marshal returns the ADEXP text of m. Empty strings, nil pointers and
+// nil slices are left out.
+func (m *BFD) Marshal() (string, error) {
+	return adexp.Marshal(m.toMap(), bfdSchema)
+}
+
+func (m *BFD) fromMap(data map[string]interface{}) {
+	m.TITLE, _ = data["TITLE"].(string)
+	if v, ok := data["REFDATA"].(map[string]interface{}); ok {
+		m.REFDATA = new(BFD_REFDATA)
+		m.REFDATA.fromMap(v)
+	}
+	m.ARCID, _ = data["ARCID"].(string)
+	m.SSRCODE, _ = data["SSRCODE"].(string)
+	m.ADEP, _ = data["ADEP"].(string)
+	m.ADES, _ = data["ADES"].(string)
+	m.ARCTYP, _ = data["ARCTYP"].(string)
+	m.NBARC, _ = data["NBARC"].(string)
+	m.WKTRC, _ = data["WKTRC"].(string)
+	m.FLTRUL, _ = data["FLTRUL"].(string)
+	m.FLTTYP, _ = data["FLTTYP"].(string)
+	m.IFPLID, _ = data["IFPLID"].(string)
+	m.EOBD, _ = data["EOBD"].(string)
+	m.EOBT, _ = data["EOBT"].(string)
+	m.ELDT, _ = data["ELDT"].(string)
+	m.FPLCAT, _ = data["FPLCAT"].(string)
+	m.CTOT, _ = data["CTOT"].(string)
+	m.SID, _ = data["SID"].(string)
+	m.STAR, _ = data["STAR"].(string)
+	m.REG, _ = data["REG"].(string)
+	m.ROUTE, _ = data["ROUTE"].(string)
+	if v, ok := data["RTEPTS"].([]interface{}); ok {
+		m.RTEPTS = make([]BFD_RTEPTS, 0, len(v))
+		for _, item := range v {
+			if item, ok := item.(map[string]interface{}); ok {
+				var x BFD_RTEPTS
+				x.fromMap(item)
+				m.RTEPTS = append(m.RTEPTS, x)
+			}
+		}
+	}
+	if v, ok := data["CFL"].(map[string]interface{}); ok {
+		m.CFL = new(BFD_CFL)
+		m.CFL.fromMap(v)
+	}
+	m.RFL, _ = data["RFL"].(string)
+	m.SPEED, _ = data["SPEED"].(string)
+	m.TTLEET, _ = data["TTLEET"].(string)
+	if v, ok := data["COORDATA"].(map[string]interface{}); ok {
+		m.COORDATA = new(BFD_COORDATA)
+		m.COORDATA.fromMap(v)
+	}
+	m.CEQPT, _ = data["CEQPT"].(string)
+	m.SEQPT, _ = data["SEQPT"].(string)
+	if v, ok := data["EQCST"].([]interface{}); ok {
+		m.EQCST = make([]string, 0, len(v))
+		for _, item := range v {
+			if item, ok := item.(string); ok {
+				m.EQCST = append(m.EQCST, item)
+			}
+		}
+	}
+	m.OPR, _ = data["OPR"].(string)
+	if v, ok := data["ALTRNT1"].(adexp.LocationIndicator); ok {
+		m.ALTRNT1 = &v
+	}
+	if v, ok := data["ALTRNT2"].(adexp.LocationIndicator); ok {
+		m.ALTRNT2 = &v
+	}
+	m.RMK, _ = data["RMK"].(string)
+}
+
+func (m *BFD) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.TITLE != "" {
+		data["TITLE"] = m.TITLE
+	}
+	if m.REFDATA != nil {
+		data["REFDATA"] = m.REFDATA.toMap()
+	}
+	if m.ARCID != "" {
+		data["ARCID"] = m.ARCID
+	}
+	if m.SSRCODE != "" {
+		data["SSRCODE"] = m.SSRCODE
+	}
+	if m.ADEP != "" {
+		data["ADEP"] = m.ADEP
+	}
+	if m.ADES != "" {
+		data["ADES"] = m.ADES
+	}
+	if m.ARCTYP != "" {
+		data["ARCTYP"] = m.ARCTYP
+	}
+	if m.NBARC != "" {
+		data["NBARC"] = m.NBARC
+	}
+	if m.WKTRC != "" {
+		data["WKTRC"] = m.WKTRC
+	}
+	if m.FLTRUL != "" {
+		data["FLTRUL"] = m.FLTRUL
+	}
+	if m.FLTTYP != "" {
+		data["FLTTYP"] = m.FLTTYP
+	}
+	if m.IFPLID != "" {
+		data["IFPLID"] = m.IFPLID
+	}
+	if m.EOBD != "" {
+		data["EOBD"] = m.EOBD
+	}
+	if m.EOBT != "" {
+		data["EOBT"] = m.EOBT
+	}
+	if m.ELDT != "" {
+		data["ELDT"] = m.ELDT
+	}
+	if m.FPLCAT != "" {
+		data["FPLCAT"] = m.FPLCAT
+	}
+	if m.CTOT != "" {
+		data["CTOT"] = m.CTOT
+	}
+	if m.SID != "" {
+		data["SID"] = m.SID
+	}
+	if m.STAR != "" {
+		data["STAR"] = m.STAR
+	}
+	if m.REG != "" {
+		data["REG"] = m.REG
+	}
+	if m.ROUTE != "" {
+		data["ROUTE"] = m.ROUTE
+	}
+	if m.RTEPTS != nil {
+		items := make([]interface{}, len(m.RTEPTS))
+		for i := range m.RTEPTS {
+			items[i] = m.RTEPTS[i].toMap()
+		}
+		data["RTEPTS"] = items
+	}
+	if m.CFL != nil {
+		data["CFL"] = m.CFL.toMap()
+	}
+	if m.RFL != "" {
+		data["RFL"] = m.RFL
+	}
+	if m.SPEED != "" {
+		data["SPEED"] = m.SPEED
+	}
+	if m.TTLEET != "" {
+		data["TTLEET"] = m.TTLEET
+	}
+	if m.COORDATA != nil {
+		data["COORDATA"] = m.COORDATA.toMap()
+	}
+	if m.CEQPT != "" {
+		data["CEQPT"] = m.CEQPT
+	}
+	if m.SEQPT != "" {
+		data["SEQPT"] = m.SEQPT
+	}
+	if m.EQCST != nil {
+		items := make([]interface{}, len(m.EQCST))
+		for i := range m.EQCST {
+			items[i] = m.EQCST[i]
+		}
+		data["EQCST"] = items
+	}
+	if m.OPR != "" {
+		data["OPR"] = m.OPR
+	}
+	if m.ALTRNT1 != nil {
+		data["ALTRNT1"] = *m.ALTRNT1
+	}
+	if m.ALTRNT2 != nil {
+		data["ALTRNT2"] = *m.ALTRNT2
+	}
+	if m.RMK != "" {
+		data["RMK"] = m.RMK
+	}
+	return data
+}
+
+// BFD_REFDATA is the structured field REFDATA
+type BFD_REFDATA struct {
+	// Sender of the message
+	SENDER *BFD_REFDATA_SENDER
+	// Receiver of the message
+	RECVR *BFD_REFDATA_RECVR
+	// Sequence number
+	SEQNUM string
+}
+
+func (m *BFD_REFDATA) fromMap(data map[string]interface{}) {
+	if v, ok := data["SENDER"].(map[string]interface{}); ok {
+		m.SENDER = new(BFD_REFDATA_SENDER)
+		m.SENDER.fromMap(v)
+	}
+	if v, ok := data["RECVR"].(map[string]interface{}); ok {
+		m.RECVR = new(BFD_REFDATA_RECVR)
+		m.RECVR.fromMap(v)
+	}
+	m.SEQNUM, _ = data["SEQNUM"].(string)
+}
+
+func (m *BFD_REFDATA) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.SENDER != nil {
+		data["SENDER"] = m.SENDER.toMap()
+	}
+	if m.RECVR != nil {
+		data["RECVR"] = m.RECVR.toMap()
+	}
+	if m.SEQNUM != "" {
+		data["SEQNUM"] = m.SEQNUM
+	}
+	return data
+}
+
+// BFD_RTEPTS is an item of the list RTEPTS
+type BFD_RTEPTS struct {
+	// Route point
+	PT *BFD_RTEPTS_PT
+}
+
+func (m *BFD_RTEPTS) fromMap(data map[string]interface{}) {
+	if v, ok := data["PT"].(map[string]interface{}); ok {
+		m.PT = new(BFD_RTEPTS_PT)
+		m.PT.fromMap(v)
+	}
+}
+
+func (m *BFD_RTEPTS) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.PT != nil {
+		data["PT"] = m.PT.toMap()
+	}
+	return data
+}
+
+// BFD_CFL is the structured field CFL
+type BFD_CFL struct {
+	// Flight level
+	FL string
+}
+
+func (m *BFD_CFL) fromMap(data map[string]interface{}) {
+	m.FL, _ = data["FL"].(string)
+}
+
+func (m *BFD_CFL) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.FL != "" {
+		data["FL"] = m.FL
+	}
+	return data
+}
+
+// BFD_COORDATA is the structured field COORDATA
+type BFD_COORDATA struct {
+	// Point id
+	PTID string
+	// Time over
+	TO string
+	// Transfer flight level
+	TFL string
+}
+
+func (m *BFD_COORDATA) fromMap(data map[string]interface{}) {
+	m.PTID, _ = data["PTID"].(string)
+	m.TO, _ = data["TO"].(string)
+	m.TFL, _ = data["TFL"].(string)
+}
+
+func (m *BFD_COORDATA) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.PTID != "" {
+		data["PTID"] = m.PTID
+	}
+	if m.TO != "" {
+		data["TO"] = m.TO
+	}
+	if m.TFL != "" {
+		data["TFL"] = m.TFL
+	}
+	return data
+}
+
+// BFD_REFDATA_SENDER is the structured field SENDER
+type BFD_REFDATA_SENDER struct {
+	// Facility
+	FAC string
+}
+
+func (m *BFD_REFDATA_SENDER) fromMap(data map[string]interface{}) {
+	m.FAC, _ = data["FAC"].(string)
+}
+
+func (m *BFD_REFDATA_SENDER) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.FAC != "" {
+		data["FAC"] = m.FAC
+	}
+	return data
+}
+
+// BFD_REFDATA_RECVR is the structured field RECVR
+type BFD_REFDATA_RECVR struct {
+	// Facility
+	FAC string
+}
+
+func (m *BFD_REFDATA_RECVR) fromMap(data map[string]interface{}) {
+	m.FAC, _ = data["FAC"].(string)
+}
+
+func (m *BFD_REFDATA_RECVR) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.FAC != "" {
+		data["FAC"] = m.FAC
+	}
+	return data
+}
+
+// BFD_RTEPTS_PT is the structured field PT
+type BFD_RTEPTS_PT struct {
+	// Point id
+	PTID string
+	// Time over
+	TO string
+	// Flight level
+	FL string
+	// Supplementary flight level
+	SFL string
+}
+
+func (m *BFD_RTEPTS_PT) fromMap(data map[string]interface{}) {
+	m.PTID, _ = data["PTID"].(string)
+	m.TO, _ = data["TO"].(string)
+	m.FL, _ = data["FL"].(string)
+	m.SFL, _ = data["SFL"].(string)
+}
+
+func (m *BFD_RTEPTS_PT) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.PTID != "" {
+		data["PTID"] = m.PTID
+	}
+	if m.TO != "" {
+		data["TO"] = m.TO
+	}
+	if m.FL != "" {
+		data["FL"] = m.FL
+	}
+	if m.SFL != "" {
+		data["SFL"] = m.SFL
+	}
+	return data
+}
+
+// bfdSchema is the BFD schema of the message set custom
+var bfdSchema = adexp.StandardSchema{
+	Name:     "custom",
+	Category: "BFD",
+	Version:  "0.1",
+	Items: []adexp.DataField{
+		{FRN: 1, DataItem: "TITLE", Mendatory: true},
+		{FRN: 2, DataItem: "REFDATA", Type: adexp.StructuredField, Mendatory: true, Subfields: []adexp.DataField{
+			{FRN: 1, DataItem: "SENDER", Type: adexp.StructuredField, Mendatory: true, Subfields: []adexp.DataField{
+				{FRN: 1, DataItem: "FAC", Mendatory: true},
+			}},
+			{FRN: 2, DataItem: "RECVR", Type: adexp.StructuredField, Mendatory: true, Subfields: []adexp.DataField{
+				{FRN: 1, DataItem: "FAC", Mendatory: true},
+			}},
+			{FRN: 3, DataItem: "SEQNUM", Mendatory: true},
+		}},
+		{FRN: 3, DataItem: "ARCID", Mendatory: true},
+		{FRN: 4, DataItem: "SSRCODE"},
+		{FRN: 5, DataItem: "ADEP"},
+		{FRN: 6, DataItem: "ADES"},
+		{FRN: 7, DataItem: "ARCTYP"},
+		{FRN: 8, DataItem: "NBARC"},
+		{FRN: 9, DataItem: "WKTRC"},
+		{FRN: 10, DataItem: "FLTRUL"},
+		{FRN: 11, DataItem: "FLTTYP"},
+		{FRN: 12, DataItem: "IFPLID"},
+		{FRN: 13, DataItem: "EOBD"},
+		{FRN: 14, DataItem: "EOBT"},
+		{FRN: 15, DataItem: "ELDT"},
+		{FRN: 16, DataItem: "FPLCAT"},
+		{FRN: 17, DataItem: "CTOT"},
+		{FRN: 18, DataItem: "SID"},
+		{FRN: 19, DataItem: "STAR"},
+		{FRN: 20, DataItem: "REG"},
+		{FRN: 21, DataItem: "ROUTE"},
+		{FRN: 22, DataItem: "RTEPTS", Type: adexp.ListField, Subfields: []adexp.DataField{
+			{FRN: 1, DataItem: "PT", Type: adexp.StructuredField, Subfields: []adexp.DataField{
+				{FRN: 1, DataItem: "PTID"},
+				{FRN: 2, DataItem: "TO"},
+				{FRN: 3, DataItem: "FL"},
+				{FRN: 4, DataItem: "SFL"},
+			}},
+		}},
+		{FRN: 23, DataItem: "CFL", Type: adexp.StructuredField, Subfields: []adexp.DataField{
+			{FRN: 1, DataItem: "FL"},
+		}},
+		{FRN: 24, DataItem: "RFL"},
+		{FRN: 25, DataItem: "SPEED"},
+		{FRN: 26, DataItem: "TTLEET"},
+		{FRN: 27, DataItem: "COORDATA", Type: adexp.StructuredField, Subfields: []adexp.DataField{
+			{FRN: 1, DataItem: "PTID"},
+			{FRN: 2, DataItem: "TO"},
+			{FRN: 3, DataItem: "TFL"},
+		}},
+		{FRN: 28, DataItem: "CEQPT"},
+		{FRN: 29, DataItem: "SEQPT"},
+		{FRN: 30, DataItem: "EQCST", Type: adexp.ListField, Subfields: []adexp.DataField{
+			{FRN: 1, DataItem: "EQPT"},
+		}},
+		{FRN: 31, DataItem: "OPR"},
+		{FRN: 32, DataItem: "ALTRNT1", Format: adexp.FormatLocation},
+		{FRN: 33, DataItem: "ALTRNT2", Format: adexp.FormatLocation},
+		{FRN: 34, DataItem: "RMK"},
+	},
+}
+
+var bfdParser = adexp.NewParserWithOpts([]adexp.MessageSet{{Name: "custom", Set: map[string]adexp.StandardSchema{"BFD": bfdSchema}}}, adexp.ParserOpts{IgnoreTargets: true})
+
+// TFD is a message of the ADEXP title TFD version 0.1
+type TFD struct {
+	// Title of the ADEXP Message
+	TITLE string
+	// Message Reference with sender, receiver and sequence number
+	REFDATA *TFD_REFDATA
+	// Aircraft id or callsign
+	ARCID string
+	// Assigned SSRCODE
+	SSRCODE string
+	// Aerodrom of departure
+	ADEP string
+	// Aerodrom of destination
+	ADES string
+	// Aircraft type
+	ARCTYP string
+	// Individual flight plan id
+	IFPLID string
+	// Estimated off block time
+	EOBT string
+	// Estimated landing time
+	ELDT string
+	// Day of Flight
+	EOBD string
+}
+
+// Unmarshal parses the TFD message into m
+func (m *TFD) Unmarshal(message string) error {
+	fp, err := tfdParser.Parse(message)
+	if err != nil {
+		return err
+	}
+	*m = TFD{}
+	m.fromMap(fp)
+	return nil
+}
+
+// Marshal returns the ADEXP text of m. Empty strings, nil pointers and
+// nil slices are left out.
+func (m *TFD) Marshal() (string, error) {
+	return adexp.Marshal(m.toMap(), tfdSchema)
+}
+
+func (m *TFD) fromMap(data map[string]interface{}) {
+	m.TITLE, _ = data["TITLE"].(string)
+	if v, ok := data["REFDATA"].(map[string]interface{}); ok {
+		m.REFDATA = new(TFD_REFDATA)
+		m.REFDATA.fromMap(v)
+	}
+	m.ARCID, _ = data["ARCID"].(string)
+	m.SSRCODE, _ = data["SSRCODE"].(string)
+	m.ADEP, _ = data["ADEP"].(string)
+	m.ADES, _ = data["ADES"].(string)
+	m.ARCTYP, _ = data["ARCTYP"].(string)
+	m.IFPLID, _ = data["IFPLID"].(string)
+	m.EOBT, _ = data["EOBT"].(string)
+	m.ELDT, _ = data["ELDT"].(string)
+	m.EOBD, _ = data["EOBD"].(string)
+}
+
+func (m *TFD) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.TITLE != "" {
+		data["TITLE"] = m.TITLE
+	}
+	if m.REFDATA != nil {
+		data["REFDATA"] = m.REFDATA.toMap()
+	}
+	if m.ARCID != "" {
+		data["ARCID"] = m.ARCID
+	}
+	if m.SSRCODE != "" {
+		data["SSRCODE"] = m.SSRCODE
+	}
+	if m.ADEP != "" {
+		data["ADEP"] = m.ADEP
+	}
+	if m.ADES != "" {
+		data["ADES"] = m.ADES
+	}
+	if m.ARCTYP != "" {
+		data["ARCTYP"] = m.ARCTYP
+	}
+	if m.IFPLID != "" {
+		data["IFPLID"] = m.IFPLID
+	}
+	if m.EOBT != "" {
+		data["EOBT"] = m.EOBT
+	}
+	if m.ELDT != "" {
+		data["ELDT"] = m.ELDT
+	}
+	if m.EOBD != "" {
+		data["EOBD"] = m.EOBD
+	}
+	return data
+}
+
+// TFD_REFDATA is the structured field REFDATA
+type TFD_REFDATA struct {
+	// Message Reference with sender, receiver and sequence number
+	SENDER *TFD_REFDATA_SENDER
+	// Message Reference with sender, receiver and sequence number
+	RECVR *TFD_REFDATA_RECVR
+	// Title of the ADEXP Message
+	SEQNUM string
+}
+
+func (m *TFD_REFDATA) fromMap(data map[string]interface{}) {
+	if v, ok := data["SENDER"].(map[string]interface{}); ok {
+		m.SENDER = new(TFD_REFDATA_SENDER)
+		m.SENDER.fromMap(v)
+	}
+	if v, ok := data["RECVR"].(map[string]interface{}); ok {
+		m.RECVR = new(TFD_REFDATA_RECVR)
+		m.RECVR.fromMap(v)
+	}
+	m.SEQNUM, _ = data["SEQNUM"].(string)
+}
+
+func (m *TFD_REFDATA) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.SENDER != nil {
+		data["SENDER"] = m.SENDER.toMap()
+	}
+	if m.RECVR != nil {
+		data["RECVR"] = m.RECVR.toMap()
+	}
+	if m.SEQNUM != "" {
+		data["SEQNUM"] = m.SEQNUM
+	}
+	return data
+}
+
+// TFD_REFDATA_SENDER is the structured field SENDER
+type TFD_REFDATA_SENDER struct {
+	// Message Reference with sender, receiver and sequence number
+	FAC string
+}
+
+func (m *TFD_REFDATA_SENDER) fromMap(data map[string]interface{}) {
+	m.FAC, _ = data["FAC"].(string)
+}
+
+func (m *TFD_REFDATA_SENDER) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.FAC != "" {
+		data["FAC"] = m.FAC
+	}
+	return data
+}
+
+// TFD_REFDATA_RECVR is the structured field RECVR
+type TFD_REFDATA_RECVR struct {
+	// Message Reference with sender, receiver and sequence number
+	FAC string
+}
+
+func (m *TFD_REFDATA_RECVR) fromMap(data map[string]interface{}) {
+	m.FAC, _ = data["FAC"].(string)
+}
+
+func (m *TFD_REFDATA_RECVR) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.FAC != "" {
+		data["FAC"] = m.FAC
+	}
+	return data
+}
+
+// tfdSchema is the TFD schema of the message set custom
+var tfdSchema = adexp.StandardSchema{
+	Name:     "custom",
+	Category: "TFD",
+	Version:  "0.1",
+	Items: []adexp.DataField{
+		{FRN: 1, DataItem: "TITLE", Mendatory: true},
+		{FRN: 2, DataItem: "REFDATA", Type: adexp.StructuredField, Mendatory: true, Subfields: []adexp.DataField{
+			{FRN: 2, DataItem: "SENDER", Type: adexp.StructuredField, Mendatory: true, Subfields: []adexp.DataField{
+				{FRN: 2, DataItem: "FAC", Mendatory: true},
+			}},
+			{FRN: 2, DataItem: "RECVR", Type: adexp.StructuredField, Mendatory: true, Subfields: []adexp.DataField{
+				{FRN: 2, DataItem: "FAC", Mendatory: true},
+			}},
+			{FRN: 1, DataItem: "SEQNUM", Mendatory: true},
+		}},
+		{FRN: 3, DataItem: "ARCID", Mendatory: true},
+		{FRN: 4, DataItem: "SSRCODE"},
+		{FRN: 5, DataItem: "ADEP"},
+		{FRN: 6, DataItem: "ADES"},
+		{FRN: 7, DataItem: "ARCTYP"},
+		{FRN: 8, DataItem: "IFPLID"},
+		{FRN: 9, DataItem: "EOBT"},
+		{FRN: 10, DataItem: "ELDT"},
+		{FRN: 12, DataItem: "EOBD"},
+	},
+}
+
+var tfdParser = adexp.NewParserWithOpts([]adexp.MessageSet{{Name: "custom", Set: map[string]adexp.StandardSchema{"TFD": tfdSchema}}}, adexp.ParserOpts{IgnoreTargets: true})
+
+// OFP is a message of the ADEXP title OFP version 0.1
+type OFP struct {
+	// Title of the ADEXP Message
+	TITLE string
+	// Originator of the operational flight plan
+	ORIGIN *OFP_ORIGIN
+	// Callsign
+	CALLSIGN string
+	// Departure aerodrome
+	DEP *adexp.LocationIndicator
+	// Destination aerodrome
+	DEST *adexp.LocationIndicator
+	// Off block time
+	OBT *adexp.Time
+	// Waypoints
+	WPTS []OFP_WPTS
+	// Number of passengers
+	PAX string
+}
+
+// Unmarshal parses the OFP message into m
+func (m *OFP) Unmarshal(message string) error {
+	fp, err := ofpParser.Parse(message)
+	if err != nil {
+		return err
+	}
+	*m = OFP{}
+	m.fromMap(fp)
+	return nil
+}
+
+// Marshal returns the ADEXP text of m. Empty strings, nil pointers and
+// nil slices are left out.
+func (m *OFP) Marshal() (string, error) {
+	return adexp.Marshal(m.toMap(), ofpSchema)
+}
+
+func (m *OFP) fromMap(data map[string]interface{}) {
+	m.TITLE, _ = data["TITLE"].(string)
+	if v, ok := data["ORIGIN"].(map[string]interface{}); ok {
+		m.ORIGIN = new(OFP_ORIGIN)
+		m.ORIGIN.fromMap(v)
+	}
+	m.CALLSIGN, _ = data["CALLSIGN"].(string)
+	if v, ok := data["DEP"].(adexp.LocationIndicator); ok {
+		m.DEP = &v
+	}
+	if v, ok := data["DEST"].(adexp.LocationIndicator); ok {
+		m.DEST = &v
+	}
+	if v, ok := data["OBT"].(adexp.Time); ok {
+		m.OBT = &v
+	}
+	if v, ok := data["WPTS"].([]interface{}); ok {
+		m.WPTS = make([]OFP_WPTS, 0, len(v))
+		for _, item := range v {
+			if item, ok := item.(map[string]interface{}); ok {
+				var x OFP_WPTS
+				x.fromMap(item)
+				m.WPTS = append(m.WPTS, x)
+			}
+		}
+	}
+	m.PAX, _ = data["PAX"].(string)
+}
+
+func (m *OFP) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.TITLE != "" {
+		data["TITLE"] = m.TITLE
+	}
+	if m.ORIGIN != nil {
+		data["ORIGIN"] = m.ORIGIN.toMap()
+	}
+	if m.CALLSIGN != "" {
+		data["CALLSIGN"] = m.CALLSIGN
+	}
+	if m.DEP != nil {
+		data["DEP"] = *m.DEP
+	}
+	if m.DEST != nil {
+		data["DEST"] = *m.DEST
+	}
+	if m.OBT != nil {
+		data["OBT"] = *m.OBT
+	}
+	if m.WPTS != nil {
+		items := make([]interface{}, len(m.WPTS))
+		for i := range m.WPTS {
+			items[i] = m.WPTS[i].toMap()
+		}
+		data["WPTS"] = items
+	}
+	if m.PAX != "" {
+		data["PAX"] = m.PAX
+	}
+	return data
+}
+
+// OFP_ORIGIN is the structured field ORIGIN
+type OFP_ORIGIN struct {
+	// Facility
+	FAC string
+}
+
+func (m *OFP_ORIGIN) fromMap(data map[string]interface{}) {
+	m.FAC, _ = data["FAC"].(string)
+}
+
+func (m *OFP_ORIGIN) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.FAC != "" {
+		data["FAC"] = m.FAC
+	}
+	return data
+}
+
+// OFP_WPTS is an item of the list WPTS
+type OFP_WPTS struct {
+	// Waypoint
+	WPT *OFP_WPTS_WPT
+}
+
+func (m *OFP_WPTS) fromMap(data map[string]interface{}) {
+	if v, ok := data["WPT"].(map[string]interface{}); ok {
+		m.WPT = new(OFP_WPTS_WPT)
+		m.WPT.fromMap(v)
+	}
+}
+
+func (m *OFP_WPTS) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.WPT != nil {
+		data["WPT"] = m.WPT.toMap()
+	}
+	return data
+}
+
+// OFP_WPTS_WPT is the structured field WPT
+type OFP_WPTS_WPT struct {
+	// Waypoint name
+	NAME string
+	// Estimated time over
+	ETO *adexp.Time
+	// Planned level
+	LVL *adexp.FlightLevel
+}
+
+func (m *OFP_WPTS_WPT) fromMap(data map[string]interface{}) {
+	m.NAME, _ = data["NAME"].(string)
+	if v, ok := data["ETO"].(adexp.Time); ok {
+		m.ETO = &v
+	}
+	if v, ok := data["LVL"].(adexp.FlightLevel); ok {
+		m.LVL = &v
+	}
+}
+
+func (m *OFP_WPTS_WPT) toMap() map[string]interface{} {
+	data := make(map[string]interface{})
+	if m.NAME != "" {
+		data["NAME"] = m.NAME
+	}
+	if m.ETO != nil {
+		data["ETO"] = *m.ETO
+	}
+	if m.LVL != nil {
+		data["LVL"] = *m.LVL
+	}
+	return data
+}
+
+// ofpSchema is the OFP schema of the message set custom
+var ofpSchema = adexp.StandardSchema{
+	Name:     "custom",
+	Category: "OFP",
+	Version:  "0.1",
+	Items: []adexp.DataField{
+		{FRN: 1, DataItem: "TITLE", Mendatory: true},
+		{FRN: 2, DataItem: "ORIGIN", Type: adexp.StructuredField, Subfields: []adexp.DataField{
+			{FRN: 1, DataItem: "FAC", Target: "REFDATA.SENDER.FAC"},
+		}},
+		{FRN: 3, DataItem: "CALLSIGN", Mendatory: true, Target: "ARCID"},
+		{FRN: 4, DataItem: "DEP", Format: adexp.FormatLocation, Target: "ADEP"},
+		{FRN: 5, DataItem: "DEST", Format: adexp.FormatLocation, Target: "ADES"},
+		{FRN: 6, DataItem: "OBT", Format: adexp.FormatTime, Target: "EOBT"},
+		{FRN: 7, DataItem: "WPTS", Type: adexp.ListField, Target: "RTEPTS", Subfields: []adexp.DataField{
+			{FRN: 1, DataItem: "WPT", Type: adexp.StructuredField, Subfields: []adexp.DataField{
+				{FRN: 1, DataItem: "NAME", Mendatory: true, Target: "PT.PTID"},
+				{FRN: 2, DataItem: "ETO", Format: adexp.FormatTime, Target: "PT.TO"},
+				{FRN: 3, DataItem: "LVL", Format: adexp.FormatFlightLevel, Target: "PT.FL"},
+			}},
+		}},
+		{FRN: 8, DataItem: "PAX"},
+	},
+}
+
+var ofpParser = adexp.NewParserWithOpts([]adexp.MessageSet{{Name: "custom", Set: map[string]adexp.StandardSchema{"OFP": ofpSchema}}}, adexp.ParserOpts{IgnoreTargets: true})
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	"github.com/davidkohl/goflightplan/adexp"
+)
+
+// formatTypes holds the Go type of the basic fields of each Format
+var formatTypes = map[adexp.Format]string{
+	adexp.FormatTime:        "adexp.Time",
+	adexp.FormatDate:        "adexp.Date",
+	adexp.FormatFlightLevel: "adexp.FlightLevel",
+	adexp.FormatSpeed:       "adexp.Speed",
+	adexp.FormatSSRCode:     "adexp.SSRCode",
+	adexp.FormatLocation:    "adexp.LocationIndicator",
+	adexp.FormatLatLong:     "adexp.LatLong",
+}
+
+// formatConstants holds the name of the adexp constant of each Format
+var formatConstants = map[adexp.Format]string{
+	adexp.FormatTime:        "adexp.FormatTime",
+	adexp.FormatDate:        "adexp.FormatDate",
+	adexp.FormatFlightLevel: "adexp.FormatFlightLevel",
+	adexp.FormatSpeed:       "adexp.FormatSpeed",
+	adexp.FormatSSRCode:     "adexp.FormatSSRCode",
+	adexp.FormatLocation:    "adexp.FormatLocation",
+	adexp.FormatLatLong:     "adexp.FormatLatLong",
+}
+
+// generator writes the Go code of the schemas of a message set
+type generator struct {
+	set *adexp.MessageSet
+	buf bytes.Buffer
+	// types holds the struct types still to be written
+	types []structType
+}
+
+// structType is a struct generated for a schema or a structured field
+type structType struct {
+	name   string
+	doc    string
+	fields []adexp.DataField
+}
+
+// generate returns the formatted source of package pkg declaring a struct
+// for each of the categories of set
+func generate(set *adexp.MessageSet, pkg string, categories []string) ([]byte, error) {
+	g := &generator{set: set}
+	g.printf("// Code generated by adexpgen from the message set %s; DO NOT EDIT.\n\n", set.Name)
+	g.printf("package %s\n\n", pkg)
+	g.printf("import \"github.com/davidkohl/goflightplan/adexp\"\n")
+
+	for _, category := range categories {
+		schema, ok := set.Set[category]
+		if !ok {
+			return nil, fmt.Errorf("no schema for category %s in message set %s", category, set.Name)
+		}
+		g.schema(schema)
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// schema writes the struct of the schema, its methods, its nested structs
+// and the variables holding the schema and its parser
+func (g *generator) schema(schema adexp.StandardSchema) {
+	name := identifier(schema.Category)
+	schemaVar := unexported(name) + "Schema"
+	parserVar := unexported(name) + "Parser"
+
+	doc := fmt.Sprintf("%s is a message of the ADEXP title %s", name, schema.Category)
+	if schema.Version != "" {
+		doc += " version " + schema.Version
+	}
+	g.structDecl(structType{name: name, doc: doc, fields: schema.Items})
+
+	g.printf("\n// Unmarshal parses the %s message into m\n", schema.Category)
+	g.printf("func (m *%s) Unmarshal(message string) error {\n", name)
+	g.printf("fp, err := %s.Parse(message)\nif err != nil {\nreturn err\n}\n", parserVar)
+	g.printf("*m = %s{}\nm.fromMap(fp)\nreturn nil\n}\n", name)
+
+	g.printf("\n// Marshal returns the ADEXP text of m. Empty strings, nil pointers and\n// nil slices are left out.\n")
+	g.printf("func (m *%s) Marshal() (string, error) {\n", name)
+	g.printf("return adexp.Marshal(m.toMap(), %s)\n}\n", schemaVar)
+
+	g.structMethods(structType{name: name, fields: schema.Items})
+	for len(g.types) > 0 {
+		t := g.types[0]
+		g.types = g.types[1:]
+		g.structDecl(t)
+		g.structMethods(t)
+	}
+
+	g.printf("\n// %s is the %s schema of the message set %s\n", schemaVar, schema.Category, g.set.Name)
+	g.printf("var %s = adexp.StandardSchema{\n", schemaVar)
+	g.printf("Name: %q,\nCategory: %q,\n", schema.Name, schema.Category)
+	if schema.Version != "" {
+		g.printf("Version: %q,\n", schema.Version)
+	}
+	g.printf("Items: ")
+	g.fieldsLiteral(schema.Items)
+	g.printf(",\n}\n")
+
+	g.printf("\nvar %s = adexp.NewParserWithOpts([]adexp.MessageSet{{Name: %q, Set: map[string]adexp.StandardSchema{%q: %s}}}, adexp.ParserOpts{IgnoreTargets: true})\n",
+		parserVar, g.set.Name, schema.Category, schemaVar)
+}
+
+// structDecl writes the struct t and queues the structs of its structured
+// fields and list items
+func (g *generator) structDecl(t structType) {
+	g.printf("\n// %s\n", t.doc)
+	g.printf("type %s struct {\n", t.name)
+	for _, field := range uniqueFields(t.fields) {
+		if field.Description != "" {
+			g.printf("// %s\n", strings.Join(strings.Fields(field.Description), " "))
+		}
+		g.printf("%s %s\n", identifier(field.DataItem), g.fieldType(t.name, field))
+	}
+	g.printf("}\n")
+}
+
+// structMethods writes the fromMap and toMap methods of the struct t
+func (g *generator) structMethods(t structType) {
+	g.printf("\nfunc (m *%s) fromMap(data map[string]interface{}) {\n", t.name)
+	for _, field := range uniqueFields(t.fields) {
+		g.fromMap(t.name, field)
+	}
+	g.printf("}\n")
+
+	g.printf("\nfunc (m *%s) toMap() map[string]interface{} {\n", t.name)
+	g.printf("data := make(map[string]interface{})\n")
+	for _, field := range uniqueFields(t.fields) {
+		g.toMap(field)
+	}
+	g.printf("return data\n}\n")
+}
+
+// fieldType returns the Go type of the field of the struct parent and
+// queues the struct type it needs
+func (g *generator) fieldType(parent string, field adexp.DataField) string {
+	switch field.Type {
+	case adexp.StructuredField:
+		name := parent + "_" + identifier(field.DataItem)
+		g.types = append(g.types, structType{name: name, doc: name + " is the structured field " + field.DataItem, fields: field.Subfields})
+		if field.Repeatable {
+			return "[]" + name
+		}
+		return "*" + name
+	case adexp.ListField:
+		if isSimpleList(field) {
+			return "[]" + basicType(field.Subfields[0])
+		}
+		name := parent + "_" + identifier(field.DataItem)
+		g.types = append(g.types, structType{name: name, doc: name + " is an item of the list " + field.DataItem, fields: field.Subfields})
+		return "[]" + name
+	default:
+		if field.Repeatable {
+			return "[]" + basicType(field)
+		}
+		if t := basicType(field); t != "string" {
+			return "*" + t
+		}
+		return "string"
+	}
+}
+
+// fromMap writes the statements setting the field from data
+func (g *generator) fromMap(parent string, field adexp.DataField) {
+	name := identifier(field.DataItem)
+	switch {
+	case field.Type == adexp.StructuredField && !field.Repeatable:
+		g.printf("if v, ok := data[%q].(map[string]interface{}); ok {\n", field.DataItem)
+		g.printf("m.%s = new(%s_%s)\nm.%s.fromMap(v)\n}\n", name, parent, name, name)
+	case field.Type == adexp.StructuredField, field.Type == adexp.ListField && !isSimpleList(field):
+		itemType := parent + "_" + name
+		g.printf("if v, ok := data[%q].([]interface{}); ok {\n", field.DataItem)
+		g.printf("m.%s = make([]%s, 0, len(v))\n", name, itemType)
+		g.printf("for _, item := range v {\nif item, ok := item.(map[string]interface{}); ok {\n")
+		g.printf("var x %s\nx.fromMap(item)\nm.%s = append(m.%s, x)\n}\n}\n}\n", itemType, name, name)
+	case field.Type == adexp.ListField, field.Repeatable:
+		elemType := basicType(field)
+		if field.Type == adexp.ListField {
+			elemType = basicType(field.Subfields[0])
+		}
+		g.printf("if v, ok := data[%q].([]interface{}); ok {\n", field.DataItem)
+		g.printf("m.%s = make([]%s, 0, len(v))\n", name, elemType)
+		g.printf("for _, item := range v {\nif item, ok := item.(%s); ok {\n", elemType)
+		g.printf("m.%s = append(m.%s, item)\n}\n}\n}\n", name, name)
+	case basicType(field) == "string":
+		g.printf("m.%s, _ = data[%q].(string)\n", name, field.DataItem)
+	default:
+		g.printf("if v, ok := data[%q].(%s); ok {\nm.%s = &v\n}\n", field.DataItem, basicType(field), name)
+	}
+}
+
+// toMap writes the statements storing the field in data
+func (g *generator) toMap(field adexp.DataField) {
+	name := identifier(field.DataItem)
+	switch {
+	case field.Type == adexp.StructuredField && !field.Repeatable:
+		g.printf("if m.%s != nil {\ndata[%q] = m.%s.toMap()\n}\n", name, field.DataItem, name)
+	case field.Type == adexp.StructuredField, field.Type == adexp.ListField && !isSimpleList(field):
+		g.printf("if m.%s != nil {\nitems := make([]interface{}, len(m.%s))\n", name, name)
+		g.printf("for i := range m.%s {\nitems[i] = m.%s[i].toMap()\n}\n", name, name)
+		g.printf("data[%q] = items\n}\n", field.DataItem)
+	case field.Type == adexp.ListField, field.Repeatable:
+		g.printf("if m.%s != nil {\nitems := make([]interface{}, len(m.%s))\n", name, name)
+		g.printf("for i := range m.%s {\nitems[i] = m.%s[i]\n}\n", name, name)
+		g.printf("data[%q] = items\n}\n", field.DataItem)
+	case basicType(field) == "string":
+		g.printf("if m.%s != \"\" {\ndata[%q] = m.%s\n}\n", name, field.DataItem, name)
+	default:
+		g.printf("if m.%s != nil {\ndata[%q] = *m.%s\n}\n", name, field.DataItem, name)
+	}
+}
+
+// fieldsLiteral writes the Go literal of fields
+func (g *generator) fieldsLiteral(fields []adexp.DataField) {
+	g.printf("[]adexp.DataField{\n")
+	for _, field := range fields {
+		g.printf("{FRN: %d, DataItem: %q", field.FRN, field.DataItem)
+		switch field.Type {
+		case adexp.ListField:
+			g.printf(", Type: adexp.ListField")
+		case adexp.StructuredField:
+			g.printf(", Type: adexp.StructuredField")
+		}
+		if field.Mendatory {
+			g.printf(", Mendatory: true")
+		}
+		if field.Repeatable {
+			g.printf(", Repeatable: true")
+		}
+		if field.Format != "" {
+			if constant, ok := formatConstants[field.Format]; ok {
+				g.printf(", Format: %s", constant)
+			} else {
+				g.printf(", Format: %q", field.Format)
+			}
+		}
+		if field.Target != "" {
+			g.printf(", Target: %q", field.Target)
+		}
+		if len(field.Subfields) > 0 {
+			g.printf(", Subfields: ")
+			g.fieldsLiteral(field.Subfields)
+		}
+		g.printf("},\n")
+	}
+	g.printf("}")
+}
+
+// uniqueFields returns fields without the repeated DataItems, which the
+// parser ignores as it uses the first field of a name
+func uniqueFields(fields []adexp.DataField) []adexp.DataField {
+	unique := make([]adexp.DataField, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !seen[field.DataItem] {
+			seen[field.DataItem] = true
+			unique = append(unique, field)
+		}
+	}
+	return unique
+}
+
+// basicType returns the Go type of the values of a basic field
+func basicType(field adexp.DataField) string {
+	if t, ok := formatTypes[field.Format]; ok {
+		return t
+	}
+	return "string"
+}
+
+// isSimpleList reports whether the items of the list field are basic values
+func isSimpleList(field adexp.DataField) bool {
+	return len(field.Subfields) == 1 && field.Subfields[0].Type == adexp.Basicfield
+}
+
+// identifier returns name as an exported Go identifier
+func identifier(name string) string {
+	id := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if id == "" || !unicode.IsUpper(rune(id[0])) {
+		id = "X" + id
+	}
+	return id
+}
+
+// unexported returns the identifier in lower case
+func unexported(id string) string {
+	return strings.ToLower(id)
+}
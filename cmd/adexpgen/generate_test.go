@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/davidkohl/goflightplan/adexp"
+)
+
+func Test_Generate(t *testing.T) {
+	set, err := adexp.MessageSetFromFS(os.DirFS("../../test/schema"), "custom")
+	if err != nil {
+		t.Fatalf("Failed to load the message set: %v", err)
+	}
+	src, err := generate(set, "example", []string{"BFD", "TFD", "OFP"})
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	expected, err := os.ReadFile("example/messages_gen.go")
+	if err != nil {
+		t.Fatalf("Failed to read the generated example: %v", err)
+	}
+	if string(src) != string(expected) {
+		t.Errorf("example/messages_gen.go is out of date, run go generate ./cmd/adexpgen/example")
+	}
+
+	if _, err := generate(set, "example", []string{"XYZ"}); err == nil || err.Error() != "no schema for category XYZ in message set custom" {
+		t.Errorf("Expected an unknown category error, got %v", err)
+	}
+}
+
+func Test_Generate_Standard(t *testing.T) {
+	set := adexp.StandardMessageSet()
+	if _, err := generate(set, "standard", sortedCategories(set)); err != nil {
+		t.Errorf("generate failed for the standard message set: %v", err)
+	}
+}
+
+func Test_Identifier(t *testing.T) {
+	for name, expected := range map[string]string{
+		"ARCID":    "ARCID",
+		"REF-DATA": "REF_DATA",
+		"3RDPARTY": "X3RDPARTY",
+		"":         "X",
+	} {
+		if id := identifier(name); id != expected {
+			t.Errorf("Expected %s for %q, got %s", expected, name, id)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Adexpgen generates Go structs for the ADEXP schemas of a message set, with
+// Unmarshal and Marshal methods using the adexp parser and encoder.
+//
+// Usage in a go:generate directive:
+//
+//	//go:generate go run github.com/davidkohl/goflightplan/cmd/adexpgen -dir schema -set custom -types BFD,TFD -o messages_gen.go
+//
+// Schemas are loaded with adexp.MessageSetFromFS from -dir, or taken from the
+// standard message set if -dir is empty. Without -types a struct is generated
+// for every schema of the set. The package defaults to $GOPACKAGE, which go
+// generate sets.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/davidkohl/goflightplan/adexp"
+)
+
+func main() {
+	dir := flag.String("dir", "", "directory of the JSON and YAML schema files, the standard message set if empty")
+	name := flag.String("set", "custom", "name of the message set")
+	types := flag.String("types", "", "comma separated categories to generate, all if empty")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package of the generated file")
+	out := flag.String("o", "adexp_gen.go", "output file")
+	flag.Parse()
+
+	if err := run(*dir, *name, *types, *pkg, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "adexpgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, name, types, pkg, out string) error {
+	if pkg == "" {
+		return fmt.Errorf("no package given, use -pkg or run from go generate")
+	}
+
+	set := adexp.StandardMessageSet()
+	if dir != "" {
+		var err error
+		if set, err = adexp.MessageSetFromFS(os.DirFS(dir), name); err != nil {
+			return err
+		}
+	}
+
+	categories := sortedCategories(set)
+	if types != "" {
+		categories = strings.Split(types, ",")
+	}
+
+	src, err := generate(set, pkg, categories)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+// sortedCategories returns the categories of the schemas of set in order
+func sortedCategories(set *adexp.MessageSet) []string {
+	categories := make([]string, 0, len(set.Set))
+	for category := range set.Set {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
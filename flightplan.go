@@ -1,23 +1,49 @@
 package goflightplan
 
 import (
+	"fmt"
 	"strings"
+
+	"github.com/davidkohl/goflightplan/aftn"
 )
 
+// MessageType is the format of a message
+type MessageType uint
+
 const (
-	MessageTypeICAO = iota
+	MessageTypeICAO MessageType = iota
 	MessageTypeADEXP
+	MessageTypeUnknown
 )
 
-func GetFlightplanFormat(s string) uint {
-	if strings.Contains(s, "-TITLE ") {
-		return 1
+func (t MessageType) String() string {
+	switch t {
+	case MessageTypeICAO:
+		return "ICAO"
+	case MessageTypeADEXP:
+		return "ADEXP"
+	}
+	return "UNKNOWN"
+}
+
+// GetFlightplanFormat detects the format of a message, with or without AFTN
+// envelope
+func GetFlightplanFormat(s string) MessageType {
+	if _, text, err := aftn.Parse(s); err == nil {
+		s = text
+	}
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "-TITLE") || strings.Contains(s, " -TITLE ") || strings.Contains(s, "\n-TITLE"):
+		return MessageTypeADEXP
+	case icaoMessage.MatchString(s):
+		return MessageTypeICAO
 	}
-	return 0
+	return MessageTypeUnknown
 }
 
 type FlightplanWrapper struct {
-	Flightplan map[string]interface{}
+	Flightplan *Flightplan
 	Meta       map[string]interface{}
 	Raw        string
 }
@@ -26,3 +52,164 @@ func NewFlightplanWrapper() *FlightplanWrapper {
 	meta := make(map[string]interface{}, 0)
 	return &FlightplanWrapper{Meta: meta}
 }
+
+// Flightplan is the typed form of a parsed flight plan. Field names follow the
+// ADEXP primary fields, which both the adexp and icao parsers use as map keys.
+// Fields holds the untyped map the parser produced, so custom or unknown fields
+// remain available.
+type Flightplan struct {
+	TITLE   string
+	ARCID   string
+	SSRCODE string
+	IFPLID  string
+	FLTRUL  string
+	FLTTYP  string
+	NBARC   string
+	ARCTYP  string
+	WKTRC   string
+	ADEP    string
+	ADES    string
+	EOBT    string
+	EOBD    string
+	DOF     string
+	EELT    string
+	ROUTE   string
+	RFL     string
+	SPEED   string
+	ALTRNT1 string
+	ALTRNT2 string
+	REG     string
+	OPR     string
+	RMK     string
+	EQCST   []string
+	REFDATA RefData
+	RTEPTS  []RoutePoint
+
+	Fields map[string]interface{}
+}
+
+// RefData is the ADEXP REFDATA structured field.
+type RefData struct {
+	SENDER string
+	RECVR  string
+	SEQNUM string
+}
+
+// RoutePoint is a single point of the ADEXP RTEPTS list.
+type RoutePoint struct {
+	PTID string
+	TO   string
+	FL   string
+	SFL  string
+}
+
+// NewFlightplan builds a Flightplan from the map returned by adexp.Parser.Parse
+// or icao.ICAOParser.Parse.
+func NewFlightplan(fields map[string]interface{}) *Flightplan {
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	fp := &Flightplan{
+		TITLE:   stringValue(fields, "TITLE"),
+		ARCID:   stringValue(fields, "ARCID"),
+		SSRCODE: stringValue(fields, "SSRCODE"),
+		IFPLID:  stringValue(fields, "IFPLID"),
+		FLTRUL:  stringValue(fields, "FLTRUL"),
+		FLTTYP:  stringValue(fields, "FLTTYP"),
+		NBARC:   stringValue(fields, "NBARC"),
+		ARCTYP:  stringValue(fields, "ARCTYP"),
+		WKTRC:   stringValue(fields, "WKTRC"),
+		ADEP:    stringValue(fields, "ADEP"),
+		ADES:    stringValue(fields, "ADES"),
+		EOBT:    stringValue(fields, "EOBT"),
+		EOBD:    stringValue(fields, "EOBD"),
+		DOF:     stringValue(fields, "DOF"),
+		EELT:    stringValue(fields, "EELT"),
+		ROUTE:   stringValue(fields, "ROUTE"),
+		RFL:     stringValue(fields, "RFL"),
+		SPEED:   stringValue(fields, "SPEED"),
+		ALTRNT1: stringValue(fields, "ALTRNT1"),
+		ALTRNT2: stringValue(fields, "ALTRNT2"),
+		REG:     stringValue(fields, "REG"),
+		OPR:     stringValue(fields, "OPR"),
+		RMK:     stringValue(fields, "RMK"),
+		Fields:  fields,
+	}
+	if fp.EELT == "" {
+		fp.EELT = stringValue(fields, "TTLEET")
+	}
+
+	if refdata, ok := fields["REFDATA"].(map[string]interface{}); ok {
+		fp.REFDATA = RefData{
+			SENDER: facilityValue(refdata, "SENDER"),
+			RECVR:  facilityValue(refdata, "RECVR"),
+			SEQNUM: stringValue(refdata, "SEQNUM"),
+		}
+	}
+
+	if eqcst, ok := fields["EQCST"].([]interface{}); ok {
+		for _, eqpt := range eqcst {
+			fp.EQCST = append(fp.EQCST, fmt.Sprint(eqpt))
+		}
+	}
+
+	if rtepts, ok := fields["RTEPTS"].([]interface{}); ok {
+		for _, item := range rtepts {
+			pt, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// Items hold a PT subfield, or its content when parsed with
+			// FlattenListItems
+			if nested, ok := pt["PT"].(map[string]interface{}); ok {
+				pt = nested
+			}
+			fp.RTEPTS = append(fp.RTEPTS, RoutePoint{
+				PTID: stringValue(pt, "PTID"),
+				TO:   stringValue(pt, "TO"),
+				FL:   stringValue(pt, "FL"),
+				SFL:  stringValue(pt, "SFL"),
+			})
+		}
+	}
+
+	return fp
+}
+
+// stringValue returns the value stored under key as a string, or "" if the
+// key is absent or holds a structured value. The occurrences of a repeated
+// basic field are joined with a space.
+func stringValue(m map[string]interface{}, key string) string {
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, occurrence := range v {
+			switch o := occurrence.(type) {
+			case string:
+				values = append(values, o)
+			case fmt.Stringer:
+				values = append(values, o.String())
+			default:
+				return ""
+			}
+		}
+		return strings.Join(values, " ")
+	case map[string]interface{}:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// facilityValue returns the FAC of a SENDER or RECVR subfield. The icao parser
+// and older schemas may store the facility directly as a string.
+func facilityValue(refdata map[string]interface{}, key string) string {
+	if fac, ok := refdata[key].(map[string]interface{}); ok {
+		return stringValue(fac, "FAC")
+	}
+	return stringValue(refdata, key)
+}
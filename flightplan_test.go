@@ -0,0 +1,153 @@
+package goflightplan
+
+import (
+	"os"
+	"testing"
+
+	"github.com/davidkohl/goflightplan/adexp"
+	"github.com/davidkohl/goflightplan/icao"
+)
+
+func Test_NewFlightplan_ADEXP(t *testing.T) {
+	parser := adexp.NewParser([]adexp.MessageSet{loadTestMessageSet(t)})
+	content, err := os.ReadFile("./test/fpl/adexp/BFD.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	fields, err := parser.Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	fp := NewFlightplan(fields)
+	if fp.TITLE != "BFD" {
+		t.Errorf("Expected TITLE to be BFD, got %v", fp.TITLE)
+	}
+	if fp.ARCID != "DLH151" {
+		t.Errorf("Expected ARCID to be DLH151, got %v", fp.ARCID)
+	}
+	if fp.ADEP != "EDDW" || fp.ADES != "GMME" {
+		t.Errorf("Expected ADEP/ADES to be EDDW/GMME, got %v/%v", fp.ADEP, fp.ADES)
+	}
+	if fp.REFDATA.SENDER != "EBBUZXZQ" {
+		t.Errorf("Expected REFDATA.SENDER to be EBBUZXZQ, got %v", fp.REFDATA.SENDER)
+	}
+	if fp.REFDATA.RECVR != "EBSZZXZQ" {
+		t.Errorf("Expected REFDATA.RECVR to be EBSZZXZQ, got %v", fp.REFDATA.RECVR)
+	}
+	if fp.REFDATA.SEQNUM != "006" {
+		t.Errorf("Expected REFDATA.SEQNUM to be 006, got %v", fp.REFDATA.SEQNUM)
+	}
+	if len(fp.RTEPTS) != 3 {
+		t.Fatalf("Expected 3 route points, got %d", len(fp.RTEPTS))
+	}
+	if fp.RTEPTS[1] != (RoutePoint{PTID: "CIV", TO: "1239", FL: "F330"}) {
+		t.Errorf("Unexpected route point: %+v", fp.RTEPTS[1])
+	}
+	if len(fp.EQCST) != 2 || fp.EQCST[0] != "W/EQ" || fp.EQCST[1] != "Y/NO" {
+		t.Errorf("Expected EQCST to be [W/EQ Y/NO], got %v", fp.EQCST)
+	}
+	if cfl, ok := fp.Fields["CFL"].(map[string]interface{}); !ok || cfl["FL"] != "F230" {
+		t.Errorf("Expected CFL to be available in Fields, got %v", fp.Fields["CFL"])
+	}
+}
+
+func Test_NewFlightplan_ICAO(t *testing.T) {
+	parser := icao.NewParser(icao.ParserOpts{})
+	content, err := os.ReadFile("./test/fpl/icao/CNL.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	fields, err := parser.Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	fp := NewFlightplan(fields)
+	if fp.TITLE != "CNL" {
+		t.Errorf("Expected TITLE to be CNL, got %v", fp.TITLE)
+	}
+	if fp.ARCID != "WMT912" {
+		t.Errorf("Expected ARCID to be WMT912, got %v", fp.ARCID)
+	}
+	if fp.ADEP != "EDJA" || fp.EOBT != "2010" || fp.ADES != "LIRF" {
+		t.Errorf("Unexpected ADEP/EOBT/ADES: %v/%v/%v", fp.ADEP, fp.EOBT, fp.ADES)
+	}
+	if fp.DOF != "240228" {
+		t.Errorf("Expected DOF to be 240228, got %v", fp.DOF)
+	}
+}
+
+func Test_NewFlightplan_Nil(t *testing.T) {
+	fp := NewFlightplan(nil)
+	if fp.Fields == nil {
+		t.Errorf("Expected Fields to be initialised")
+	}
+}
+
+func Test_ParseAFTNHeader(t *testing.T) {
+	content, err := os.ReadFile("./test/fpl/aftn/FPL.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	env, text, err := ParseAFTNHeader(string(content))
+	if err != nil {
+		t.Fatalf("ParseAFTNHeader failed: %v", err)
+	}
+	if env.Originator != "LPPRZPZX" || len(env.Addressees) != 4 {
+		t.Errorf("Unexpected envelope: %+v", env)
+	}
+	if text == "" || text[0] != '(' {
+		t.Errorf("Unexpected message text: %q", text)
+	}
+}
+
+func Test_NewFlightplan_Repeatable(t *testing.T) {
+	fp := NewFlightplan(map[string]interface{}{
+		"RMK": []interface{}{"FIRST", "SECOND"},
+		"REG": []interface{}{map[string]interface{}{"X": "Y"}},
+	})
+	if fp.RMK != "FIRST SECOND" {
+		t.Errorf("Expected RMK to be 'FIRST SECOND', got '%s'", fp.RMK)
+	}
+	if fp.REG != "" {
+		t.Errorf("Expected REG to be empty, got '%s'", fp.REG)
+	}
+}
+
+func Test_NewFlightplan_FlattenedRoutePoints(t *testing.T) {
+	fp := NewFlightplan(map[string]interface{}{
+		"RTEPTS": []interface{}{
+			map[string]interface{}{"PTID": "WOODY", "FL": "F210"},
+			map[string]interface{}{"PT": map[string]interface{}{"PTID": "CIV", "TO": "1239"}},
+		},
+	})
+	expected := []RoutePoint{{PTID: "WOODY", FL: "F210"}, {PTID: "CIV", TO: "1239"}}
+	if len(fp.RTEPTS) != 2 || fp.RTEPTS[0] != expected[0] || fp.RTEPTS[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, fp.RTEPTS)
+	}
+}
+
+func Test_NewFlightplan_Target(t *testing.T) {
+	parser := adexp.NewParser([]adexp.MessageSet{loadTestMessageSet(t)})
+	content, err := os.ReadFile("./test/fpl/adexp/OFP.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	fields, err := parser.Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	fp := NewFlightplan(fields)
+	if fp.ARCID != "DLH4AB" || fp.ADEP != "EDDF" || fp.ADES != "KJFK" {
+		t.Errorf("Expected ARCID/ADEP/ADES to be DLH4AB/EDDF/KJFK, got %v/%v/%v", fp.ARCID, fp.ADEP, fp.ADES)
+	}
+	if fp.REFDATA.SENDER != "EDDFDLHX" {
+		t.Errorf("Expected REFDATA.SENDER to be EDDFDLHX, got %v", fp.REFDATA.SENDER)
+	}
+	expected := []RoutePoint{{PTID: "TOBAK", TO: "1052", FL: "F350"}, {PTID: "MALOT", TO: "1148", FL: "F370"}}
+	if len(fp.RTEPTS) != 2 || fp.RTEPTS[0] != expected[0] || fp.RTEPTS[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, fp.RTEPTS)
+	}
+}
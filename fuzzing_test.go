@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/davidkohl/goflightplan/adexp"
@@ -15,50 +16,45 @@ func Test_Parse_Fuzz(t *testing.T) {
 	pAdexp := adexp.NewParser(testSchema)
 	pIcao := icao.NewParser(icao.ParserOpts{})
 
-	dir := "./test/fpl/fuzz"
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		t.Errorf("could not read directory: %v\n", err)
+	// The corpus is made of the valid fixtures of both formats
+	files := []string{
+		"./test/fpl/adexp/BFD.txt",
+		"./test/fpl/adexp/SAM.txt",
+		"./test/fpl/icao/CNL.txt",
+		"./test/fpl/icao/FPL.txt",
 	}
 
-	for _, file := range files {
-		// Check if it's a regular file (not a directory)
-		t.Run(file.Name(), func(t *testing.T) {
-			// Read the test file
-			if file.Type().IsRegular() {
-				// Get the full path of the file
-				filePath := dir + "/" + file.Name()
-				fmt.Println("NOW PARSING:", file.Name())
-				// Read the file's contents
-				content, err := os.ReadFile(filePath)
+	for _, filePath := range files {
+		t.Run(filepath.Base(filePath), func(t *testing.T) {
+			fmt.Println("NOW PARSING:", filepath.Base(filePath))
+			// Read the file's contents
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				fmt.Printf("could not read file %s: %v", filePath, err)
+			}
+			//Print the contents of the file
+			fpl, err := pAdexp.Parse(string(content))
+			if err == nil {
+				j, err := json.MarshalIndent(fpl, "", "\t")
 				if err != nil {
-					fmt.Printf("could not read file %s: %v", filePath, err)
-				}
-				//Print the contents of the file
-				fpl, err := pAdexp.Parse(string(content))
-				if err == nil {
-					j, err := json.MarshalIndent(fpl, "", "\t")
-					if err != nil {
-						fmt.Println(err)
-					}
-					fmt.Printf("%v\n\n", string(j))
-					fmt.Printf("%v\n", string(content))
-					return
+					fmt.Println(err)
 				}
+				fmt.Printf("%v\n\n", string(j))
+				fmt.Printf("%v\n", string(content))
+				return
+			}
 
-				fpl, err = pIcao.Parse(string(content))
-				if err == nil {
-					j, err := json.MarshalIndent(fpl, "", "\t")
-					if err != nil {
-						fmt.Println(err)
-					}
-					fmt.Printf("----------------------\n\n%v\n\n", string(j))
-					fmt.Printf("%v\n\n-----------------------------", string(content))
-					return
+			fpl, err = pIcao.Parse(string(content))
+			if err == nil {
+				j, err := json.MarshalIndent(fpl, "", "\t")
+				if err != nil {
+					fmt.Println(err)
 				}
-				t.Errorf("expected error to be nil after all parsers")
-
+				fmt.Printf("----------------------\n\n%v\n\n", string(j))
+				fmt.Printf("%v\n\n-----------------------------", string(content))
+				return
 			}
+			t.Errorf("expected error to be nil after all parsers")
 
 		})
 
@@ -0,0 +1,377 @@
+package icao
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LineWidth is the maximum number of characters per line of an encoded message
+const LineWidth = 69
+
+// Item18Indicators lists the item 18 indicators in the order they are written
+var Item18Indicators = []string{
+	"STS", "PBN", "NAV", "COM", "DAT", "SUR", "DEP", "DEST", "DOF", "REG", "EET", "SEL",
+	"TYP", "CODE", "DLE", "OPR", "ORGN", "PER", "ALTN", "RALT", "TALT", "RIF", "RMK",
+}
+
+// Item19Indicators lists the item 19 indicators in the order they are written
+var Item19Indicators = []string{"E", "P", "R", "S", "J", "D", "A", "N", "C"}
+
+type EncodeHandler struct {
+	Fn   func(fp map[string]interface{}) ([]string, error)
+	Name string
+}
+
+// Encoder writes ICAO ATS messages to an output stream
+type Encoder struct {
+	EncodeHandlers map[string]EncodeHandler
+	w              io.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	e := &Encoder{
+		EncodeHandlers: make(map[string]EncodeHandler),
+		w:              w,
+	}
+
+	e.EncodeHandlers["FPL"] = EncodeHandler{Name: "FPL", Fn: encodeFPL}
+	e.EncodeHandlers["CHG"] = EncodeHandler{Name: "CHG", Fn: encodeCHG}
+	e.EncodeHandlers["CNL"] = EncodeHandler{Name: "CNL", Fn: encodeCNL}
+	e.EncodeHandlers["DLA"] = EncodeHandler{Name: "DLA", Fn: encodeDLA}
+	e.EncodeHandlers["ARR"] = EncodeHandler{Name: "ARR", Fn: encodeARR}
+	e.EncodeHandlers["DEP"] = EncodeHandler{Name: "DEP", Fn: encodeDEP}
+
+	return e
+}
+
+// Encode writes the ICAO message for fp, selected by its TITLE, followed by a newline
+func (e *Encoder) Encode(fp map[string]interface{}) error {
+	s, err := e.marshal(fp)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(e.w, s+"\n")
+	return err
+}
+
+// Marshal returns the ICAO message for fp, selected by its TITLE. fp uses the
+// same ADEXP field names that ICAOParser.Parse produces.
+func Marshal(fp map[string]interface{}) (string, error) {
+	return NewEncoder(nil).marshal(fp)
+}
+
+func (e *Encoder) marshal(fp map[string]interface{}) (string, error) {
+	title := value(fp, "TITLE")
+	if title == "" {
+		return "", errors.New("TITLE field not found in the flight plan")
+	}
+	handler, ok := e.EncodeHandlers[title]
+	if !ok {
+		return "", fmt.Errorf("no encode handler for message type: %s", title)
+	}
+	items, err := handler.Fn(fp)
+	if err != nil {
+		return "", err
+	}
+	return wrap("("+title+"-"+strings.Join(items, "-")+")", LineWidth), nil
+}
+
+// (FPL-7-8-9-10-13-15-16-18-19)
+func encodeFPL(fp map[string]interface{}) ([]string, error) {
+	var items []string
+	for _, fn := range []func(map[string]interface{}) (string, error){
+		encodeItem7, encodeItem8, encodeItem9, encodeItem10, encodeItem13, encodeItem15, encodeItem16,
+	} {
+		item, err := fn(fp)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	items = append(items, encodeItem18(fp, Item18Indicators))
+	if item19 := encodeItem19(fp); item19 != "" {
+		items = append(items, item19)
+	}
+	return items, nil
+}
+
+// (CHG-7-13-16-18-22...)
+func encodeCHG(fp map[string]interface{}) ([]string, error) {
+	items, err := encodeReference(fp, "EOBT")
+	if err != nil {
+		return nil, err
+	}
+
+	amendments, _ := fp["AMEND"].(map[string]interface{})
+	if len(amendments) == 0 {
+		return nil, errors.New("item 22: no amendments in CHG message")
+	}
+	keys := make([]string, 0, len(amendments))
+	for k := range amendments {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(keys[i])
+		b, _ := strconv.Atoi(keys[j])
+		return a < b
+	})
+	for _, k := range keys {
+		items = append(items, k+"/"+value(amendments, k))
+	}
+	return items, nil
+}
+
+// (CNL-7-13-16-18)
+func encodeCNL(fp map[string]interface{}) ([]string, error) {
+	return encodeReference(fp, "EOBT")
+}
+
+// (DLA-7-13-16-18)
+func encodeDLA(fp map[string]interface{}) ([]string, error) {
+	return encodeReference(fp, "EOBT")
+}
+
+// (DEP-7-13-16-18), item 13 carries the actual time of departure
+func encodeDEP(fp map[string]interface{}) ([]string, error) {
+	timeKey := "ATD"
+	if value(fp, timeKey) == "" {
+		timeKey = "EOBT"
+	}
+	return encodeReference(fp, timeKey)
+}
+
+// (ARR-7-13-16-17), item 16 is only present for a diversion
+func encodeARR(fp map[string]interface{}) ([]string, error) {
+	item7, err := encodeItem7(fp)
+	if err != nil {
+		return nil, err
+	}
+	item13, err := encodeTimedAerodrome(fp, 13, "ADEP", "EOBT")
+	if err != nil {
+		return nil, err
+	}
+	items := []string{item7, item13}
+
+	adarr := value(fp, "ADARR")
+	if ades := value(fp, "ADES"); ades != "" && adarr != "" && ades != adarr {
+		items = append(items, ades)
+	}
+	item17, err := encodeTimedAerodrome(fp, 17, "ADARR", "ATA")
+	if err != nil {
+		return nil, err
+	}
+	return append(items, item17), nil
+}
+
+// encodeReference builds items 7, 13, 16 and 18 as used by the messages
+// referring to an existing flight plan
+func encodeReference(fp map[string]interface{}, timeKey string) ([]string, error) {
+	item7, err := encodeItem7(fp)
+	if err != nil {
+		return nil, err
+	}
+	item13, err := encodeTimedAerodrome(fp, 13, "ADEP", timeKey)
+	if err != nil {
+		return nil, err
+	}
+	ades := value(fp, "ADES")
+	if ades == "" {
+		return nil, errors.New("item 16: missing ADES")
+	}
+	return []string{item7, item13, ades, encodeItem18(fp, []string{"DOF"})}, nil
+}
+
+// Item 7: aircraft identification and optional SSR mode and code
+func encodeItem7(fp map[string]interface{}) (string, error) {
+	arcid := value(fp, "ARCID")
+	if arcid == "" {
+		return "", errors.New("item 7: missing ARCID")
+	}
+	if ssr := value(fp, "SSRCODE"); ssr != "" {
+		return arcid + "/" + ssr, nil
+	}
+	return arcid, nil
+}
+
+// Item 8: flight rules and type of flight
+func encodeItem8(fp map[string]interface{}) (string, error) {
+	fltrul := value(fp, "FLTRUL")
+	if fltrul == "" {
+		return "", errors.New("item 8: missing FLTRUL")
+	}
+	return fltrul + value(fp, "FLTTYP"), nil
+}
+
+// Item 9: number and type of aircraft and wake turbulence category
+func encodeItem9(fp map[string]interface{}) (string, error) {
+	arctyp := value(fp, "ARCTYP")
+	wktrc := value(fp, "WKTRC")
+	if arctyp == "" || wktrc == "" {
+		return "", errors.New("item 9: missing ARCTYP or WKTRC")
+	}
+	nbarc := value(fp, "NBARC")
+	if nbarc == "1" {
+		nbarc = ""
+	}
+	return nbarc + arctyp + "/" + wktrc, nil
+}
+
+// Item 10: equipment and capabilities, taken from CEQPT and SEQPT or built
+// from the ADEXP EQCST and SURVEQ lists
+func encodeItem10(fp map[string]interface{}) (string, error) {
+	ceqpt := value(fp, "CEQPT")
+	if ceqpt == "" {
+		ceqpt = equipmentCodes(fp["EQCST"])
+	}
+	if ceqpt == "" {
+		return "", errors.New("item 10: missing CEQPT")
+	}
+	seqpt := value(fp, "SEQPT")
+	if seqpt == "" {
+		seqpt = equipmentCodes(fp["SURVEQ"])
+	}
+	if seqpt == "" {
+		seqpt = "N"
+	}
+	return ceqpt + "/" + seqpt, nil
+}
+
+// equipmentCodes joins the codes marked as equipped ("X/EQ") in an ADEXP
+// EQCST or SURVEQ list
+func equipmentCodes(list interface{}) string {
+	entries, _ := list.([]interface{})
+	var b strings.Builder
+	for _, entry := range entries {
+		code, status, _ := strings.Cut(fmt.Sprint(entry), "/")
+		if status == "EQ" {
+			b.WriteString(code)
+		}
+	}
+	return b.String()
+}
+
+// Item 13: departure aerodrome and time
+func encodeItem13(fp map[string]interface{}) (string, error) {
+	return encodeTimedAerodrome(fp, 13, "ADEP", "EOBT")
+}
+
+// Item 15: route
+func encodeItem15(fp map[string]interface{}) (string, error) {
+	route := value(fp, "ROUTE")
+	if route == "" {
+		return "", errors.New("item 15: missing ROUTE")
+	}
+	return route, nil
+}
+
+// Item 16: destination aerodrome, total EET and alternates
+func encodeItem16(fp map[string]interface{}) (string, error) {
+	item16, err := encodeTimedAerodrome(fp, 16, "ADES", "EELT")
+	if err != nil {
+		return "", err
+	}
+	for _, key := range []string{"ALTRNT1", "ALTRNT2"} {
+		if altrnt := value(fp, key); altrnt != "" {
+			item16 += " " + altrnt
+		}
+	}
+	return item16, nil
+}
+
+// Item 18: other information, "0" if there is none
+func encodeItem18(fp map[string]interface{}, indicators []string) string {
+	var parts []string
+	for _, indicator := range indicators {
+		var v string
+		if list, ok := fp[indicator].([]interface{}); ok {
+			values := make([]string, 0, len(list))
+			for _, entry := range list {
+				values = append(values, fmt.Sprint(entry))
+			}
+			v = strings.Join(values, " ")
+		} else {
+			v = value(fp, indicator)
+		}
+		if v != "" {
+			parts = append(parts, indicator+"/"+v)
+		}
+	}
+	if len(parts) == 0 {
+		return "0"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Item 19: supplementary information, stored under SUPPINFO by indicator
+func encodeItem19(fp map[string]interface{}) string {
+	suppinfo, _ := fp["SUPPINFO"].(map[string]interface{})
+	var parts []string
+	for _, indicator := range Item19Indicators {
+		if v := value(suppinfo, indicator); v != "" {
+			parts = append(parts, indicator+"/"+v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// encodeTimedAerodrome joins a location indicator and a time, as used by items 13, 16 and 17
+func encodeTimedAerodrome(fp map[string]interface{}, item int, locKey, timeKey string) (string, error) {
+	loc := value(fp, locKey)
+	t := value(fp, timeKey)
+	if loc == "" || t == "" {
+		return "", fmt.Errorf("item %d: missing %s or %s", item, locKey, timeKey)
+	}
+	return loc + t, nil
+}
+
+// value returns the string stored under key, or "" if it is absent or
+// neither a string nor a typed value such as adexp.Time
+func value(fp map[string]interface{}, key string) string {
+	switch v := fp[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return ""
+}
+
+// wrap breaks s into lines of at most width characters. Lines are broken at
+// spaces or before the '-' starting an item; words longer than width are kept whole.
+func wrap(s string, width int) string {
+	var b strings.Builder
+	lineLen := 0
+	for _, word := range strings.Split(s, " ") {
+		for i, part := range splitItems(word) {
+			sep := ""
+			if i == 0 && lineLen > 0 {
+				sep = " "
+			}
+			if lineLen > 0 && lineLen+len(sep)+len(part) > width {
+				b.WriteByte('\n')
+				lineLen = 0
+				sep = ""
+			}
+			b.WriteString(sep + part)
+			lineLen += len(sep) + len(part)
+		}
+	}
+	return b.String()
+}
+
+// splitItems splits a word before every '-', keeping the '-' with the following part
+func splitItems(word string) []string {
+	var parts []string
+	start := 0
+	for i := 1; i < len(word); i++ {
+		if word[i] == '-' {
+			parts = append(parts, word[start:i])
+			start = i
+		}
+	}
+	return append(parts, word[start:])
+}
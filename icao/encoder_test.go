@@ -0,0 +1,221 @@
+package icao
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_Marshal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fp       map[string]interface{}
+		expected string
+	}{
+		{
+			name: "FPL",
+			fp: map[string]interface{}{
+				"TITLE":   "FPL",
+				"ARCID":   "ABC123",
+				"FLTRUL":  "I",
+				"FLTTYP":  "N",
+				"ARCTYP":  "F100",
+				"WKTRC":   "M",
+				"CEQPT":   "SRWY",
+				"SEQPT":   "C",
+				"ADEP":    "LPPR",
+				"EOBT":    "0600",
+				"ROUTE":   "N0422F340 TURON UP600 STG UN741 KEPER",
+				"ADES":    "LFPG",
+				"EELT":    "0155",
+				"ALTRNT1": "LFPO",
+				"DOF":     "060110",
+				"REG":     "DESEL",
+				"RMK":     "THIS HAS SPACE AT END",
+				"SUPPINFO": map[string]interface{}{
+					"E": "0745",
+					"P": "TBN",
+				},
+			},
+			expected: "(FPL-ABC123-IN-F100/M-SRWY/C-LPPR0600-N0422F340 TURON UP600 STG UN741\n" +
+				"KEPER-LFPG0155 LFPO-DOF/060110 REG/DESEL RMK/THIS HAS SPACE AT END\n" +
+				"-E/0745 P/TBN)",
+		},
+		{
+			name: "FPL multiple aircraft without item 18",
+			fp: map[string]interface{}{
+				"TITLE":   "FPL",
+				"ARCID":   "MIL01",
+				"SSRCODE": "A1234",
+				"FLTRUL":  "V",
+				"FLTTYP":  "M",
+				"NBARC":   "2",
+				"ARCTYP":  "F16",
+				"WKTRC":   "M",
+				"CEQPT":   "S",
+				"ADEP":    "EDDF",
+				"EOBT":    "1000",
+				"ROUTE":   "N0400VFR",
+				"ADES":    "EDDM",
+				"EELT":    "0100",
+			},
+			expected: "(FPL-MIL01/A1234-VM-2F16/M-S/N-EDDF1000-N0400VFR-EDDM0100-0)",
+		},
+		{
+			name: "FPL with ADEXP equipment lists",
+			fp: map[string]interface{}{
+				"TITLE":  "FPL",
+				"ARCID":  "DLH151",
+				"FLTRUL": "I",
+				"ARCTYP": "B737",
+				"WKTRC":  "M",
+				"EQCST":  []interface{}{"S/EQ", "W/EQ", "Y/NO"},
+				"SURVEQ": []interface{}{"L/EQ", "B1/EQ"},
+				"ADEP":   "EDDW",
+				"EOBT":   "1205",
+				"ROUTE":  "N0480F390 UB4 BNE",
+				"ADES":   "GMME",
+				"EELT":   "0300",
+			},
+			expected: "(FPL-DLH151-I-B737/M-SW/LB1-EDDW1205-N0480F390 UB4 BNE-GMME0300-0)",
+		},
+		{
+			name: "CNL",
+			fp: map[string]interface{}{
+				"TITLE": "CNL",
+				"ARCID": "WMT912",
+				"ADEP":  "EDJA",
+				"EOBT":  "2010",
+				"ADES":  "LIRF",
+				"DOF":   "240228",
+				"RMK":   "NOT IN CNL",
+			},
+			expected: "(CNL-WMT912-EDJA2010-LIRF-DOF/240228)",
+		},
+		{
+			name: "DLA",
+			fp: map[string]interface{}{
+				"TITLE": "DLA",
+				"ARCID": "WZZ5322",
+				"ADEP":  "LYNI",
+				"EOBT":  "1025",
+				"ADES":  "EDJA",
+			},
+			expected: "(DLA-WZZ5322-LYNI1025-EDJA-0)",
+		},
+		{
+			name: "DEP",
+			fp: map[string]interface{}{
+				"TITLE": "DEP",
+				"ARCID": "WZZ456",
+				"ADEP":  "BKPR",
+				"EOBT":  "1150",
+				"ATD":   "1155",
+				"ADES":  "EDJA",
+				"DOF":   date("240228"),
+			},
+			expected: "(DEP-WZZ456-BKPR1155-EDJA-DOF/240228)",
+		},
+		{
+			name: "ARR",
+			fp: map[string]interface{}{
+				"TITLE": "ARR",
+				"ARCID": "WZZ301",
+				"ADEP":  "EDJA",
+				"EOBT":  "0910",
+				"ADARR": "BKPR",
+				"ATA":   "1048",
+			},
+			expected: "(ARR-WZZ301-EDJA0910-BKPR1048)",
+		},
+		{
+			name: "ARR diverted",
+			fp: map[string]interface{}{
+				"TITLE": "ARR",
+				"ARCID": "WZZ301",
+				"ADEP":  "EDJA",
+				"EOBT":  "0910",
+				"ADES":  "LHBP",
+				"ADARR": "BKPR",
+				"ATA":   "1048",
+			},
+			expected: "(ARR-WZZ301-EDJA0910-LHBP-BKPR1048)",
+		},
+		{
+			name: "CHG",
+			fp: map[string]interface{}{
+				"TITLE": "CHG",
+				"ARCID": "ABC123",
+				"ADEP":  "LPPR",
+				"EOBT":  "0600",
+				"ADES":  "LFPG",
+				"DOF":   "060110",
+				"AMEND": map[string]interface{}{
+					"15": "N0420F350 TURON UP600 STG",
+					"9":  "B738/M",
+				},
+			},
+			expected: "(CHG-ABC123-LPPR0600-LFPG-DOF/060110-9/B738/M-15/N0420F350 TURON\nUP600 STG)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Marshal(tc.fp)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if s != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, s)
+			}
+			for _, line := range strings.Split(s, "\n") {
+				if len(line) > LineWidth {
+					t.Errorf("Line exceeds %d characters: %q", LineWidth, line)
+				}
+			}
+		})
+	}
+}
+
+func Test_Marshal_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fp       map[string]interface{}
+		expected string
+	}{
+		{name: "no title", fp: map[string]interface{}{}, expected: "TITLE field not found in the flight plan"},
+		{name: "unknown title", fp: map[string]interface{}{"TITLE": "ABC"}, expected: "no encode handler for message type: ABC"},
+		{name: "missing ARCID", fp: map[string]interface{}{"TITLE": "CNL"}, expected: "item 7: missing ARCID"},
+		{name: "missing item 13", fp: map[string]interface{}{"TITLE": "CNL", "ARCID": "A"}, expected: "item 13: missing ADEP or EOBT"},
+		{name: "missing amendments", fp: map[string]interface{}{"TITLE": "CHG", "ARCID": "A", "ADEP": "EDDF", "EOBT": "1000", "ADES": "EDDM"}, expected: "item 22: no amendments in CHG message"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Marshal(tc.fp)
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("Expected error '%s', got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func Test_Encoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	fp := map[string]interface{}{"TITLE": "CNL", "ARCID": "WMT912", "ADEP": "EDJA", "EOBT": "2010", "ADES": "LIRF", "DOF": "240228"}
+	if err := enc.Encode(fp); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if buf.String() != "(CNL-WMT912-EDJA2010-LIRF-DOF/240228)\n" {
+		t.Errorf("Unexpected output: %q", buf.String())
+	}
+}
+
+func Test_Wrap(t *testing.T) {
+	s := wrap("(FPL-"+strings.Repeat("A", 80)+"-B C)", 10)
+	expected := "(FPL\n-" + strings.Repeat("A", 80) + "\n-B C)"
+	if s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+}
@@ -0,0 +1,335 @@
+package icao
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type CapabilityKind string
+
+const (
+	CapabilityCOM      CapabilityKind = "COM"
+	CapabilityNAV      CapabilityKind = "NAV"
+	CapabilityApproach CapabilityKind = "APPROACH"
+	CapabilityOther    CapabilityKind = "OTHER"
+	CapabilitySSR      CapabilityKind = "SSR"
+	CapabilityADSB     CapabilityKind = "ADSB"
+	CapabilityADSC     CapabilityKind = "ADSC"
+)
+
+// Designator describes a single item 10 code
+type Designator struct {
+	Kind        CapabilityKind
+	Description string
+}
+
+// Item10aDesignators are the radio communication, navigation and approach aid
+// equipment and capabilities of item 10a
+var Item10aDesignators = map[string]Designator{
+	"A":  {CapabilityApproach, "GBAS landing system"},
+	"B":  {CapabilityApproach, "LPV (APV with SBAS)"},
+	"C":  {CapabilityNAV, "LORAN C"},
+	"D":  {CapabilityNAV, "DME"},
+	"E1": {CapabilityCOM, "FMC WPR ACARS"},
+	"E2": {CapabilityCOM, "D-FIS ACARS"},
+	"E3": {CapabilityCOM, "PDC ACARS"},
+	"F":  {CapabilityNAV, "ADF"},
+	"G":  {CapabilityNAV, "GNSS"},
+	"H":  {CapabilityCOM, "HF RTF"},
+	"I":  {CapabilityNAV, "Inertial navigation"},
+	"J1": {CapabilityCOM, "CPDLC ATN VDL Mode 2"},
+	"J2": {CapabilityCOM, "CPDLC FANS 1/A HFDL"},
+	"J3": {CapabilityCOM, "CPDLC FANS 1/A VDL Mode A"},
+	"J4": {CapabilityCOM, "CPDLC FANS 1/A VDL Mode 2"},
+	"J5": {CapabilityCOM, "CPDLC FANS 1/A SATCOM (INMARSAT)"},
+	"J6": {CapabilityCOM, "CPDLC FANS 1/A SATCOM (MTSAT)"},
+	"J7": {CapabilityCOM, "CPDLC FANS 1/A SATCOM (Iridium)"},
+	"K":  {CapabilityApproach, "MLS"},
+	"L":  {CapabilityApproach, "ILS"},
+	"M1": {CapabilityCOM, "ATC SATVOICE (INMARSAT)"},
+	"M2": {CapabilityCOM, "ATC SATVOICE (MTSAT)"},
+	"M3": {CapabilityCOM, "ATC SATVOICE (Iridium)"},
+	"O":  {CapabilityNAV, "VOR"},
+	"P1": {CapabilityCOM, "CPDLC RCP 400"},
+	"P2": {CapabilityCOM, "CPDLC RCP 240"},
+	"P3": {CapabilityCOM, "SATVOICE RCP 400"},
+	"R":  {CapabilityNAV, "PBN approved"},
+	"T":  {CapabilityNAV, "TACAN"},
+	"U":  {CapabilityCOM, "UHF RTF"},
+	"V":  {CapabilityCOM, "VHF RTF"},
+	"W":  {CapabilityNAV, "RVSM approved"},
+	"X":  {CapabilityNAV, "MNPS approved"},
+	"Y":  {CapabilityCOM, "VHF with 8.33 kHz channel spacing"},
+	"Z":  {CapabilityOther, "Other equipment carried or other capabilities"},
+}
+
+// Item10bDesignators are the surveillance equipment and capabilities of item 10b
+var Item10bDesignators = map[string]Designator{
+	"A":  {CapabilitySSR, "Transponder Mode A"},
+	"C":  {CapabilitySSR, "Transponder Mode A and Mode C"},
+	"E":  {CapabilitySSR, "Transponder Mode S with identification, pressure-altitude, extended squitter"},
+	"H":  {CapabilitySSR, "Transponder Mode S with identification, pressure-altitude and enhanced surveillance"},
+	"I":  {CapabilitySSR, "Transponder Mode S with identification, no pressure-altitude"},
+	"L":  {CapabilitySSR, "Transponder Mode S with identification, pressure-altitude, extended squitter and enhanced surveillance"},
+	"P":  {CapabilitySSR, "Transponder Mode S with pressure-altitude, no identification"},
+	"S":  {CapabilitySSR, "Transponder Mode S with pressure-altitude and identification"},
+	"X":  {CapabilitySSR, "Transponder Mode S without identification and pressure-altitude"},
+	"B1": {CapabilityADSB, "ADS-B out 1090 MHz"},
+	"B2": {CapabilityADSB, "ADS-B out and in 1090 MHz"},
+	"U1": {CapabilityADSB, "ADS-B out UAT"},
+	"U2": {CapabilityADSB, "ADS-B out and in UAT"},
+	"V1": {CapabilityADSB, "ADS-B out VDL Mode 4"},
+	"V2": {CapabilityADSB, "ADS-B out and in VDL Mode 4"},
+	"D1": {CapabilityADSC, "ADS-C with FANS 1/A"},
+	"G1": {CapabilityADSC, "ADS-C with ATN"},
+}
+
+// standardEquipment are the capabilities designated by "S" in item 10a
+var standardEquipment = []string{"V", "O", "L"}
+
+var ErrorEquipmentMismatch = errors.New("equipment does not match item 18")
+
+// Equipment is the decoded item 10
+type Equipment struct {
+	// Codes and SurveillanceCodes are the designators of item 10a and 10b as filed
+	Codes             []string
+	SurveillanceCodes []string
+
+	// COM, NAV and Approach hold the item 10a capabilities by kind, with "S"
+	// expanded to VHF RTF, VOR and ILS
+	COM      []string
+	NAV      []string
+	Approach []string
+	Other    []string
+
+	// SSR is the transponder designator, "" if there is none
+	SSR  string
+	ADSB []string
+	ADSC []string
+}
+
+// ParseEquipment decodes item 10, e.g. SDGW/SB1
+func ParseEquipment(s string) (*Equipment, error) {
+	m := item10Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, ErrorItemInvalid
+	}
+	e := &Equipment{
+		Codes:             splitCapabilityCodes(m[1]),
+		SurveillanceCodes: splitCapabilityCodes(m[2]),
+	}
+
+	for _, code := range e.Codes {
+		if code == "N" {
+			if len(e.Codes) > 1 {
+				return nil, fmt.Errorf("item 10a 'N' combined with other equipment: %w", ErrorItemInvalid)
+			}
+			continue
+		}
+		codes := []string{code}
+		if code == "S" {
+			codes = standardEquipment
+		}
+		for _, code := range codes {
+			designator, ok := Item10aDesignators[code]
+			if !ok {
+				return nil, fmt.Errorf("item 10a '%s': %w", code, ErrorItemInvalid)
+			}
+			switch designator.Kind {
+			case CapabilityCOM:
+				e.COM = appendUnique(e.COM, code)
+			case CapabilityNAV:
+				e.NAV = appendUnique(e.NAV, code)
+			case CapabilityApproach:
+				e.Approach = appendUnique(e.Approach, code)
+			default:
+				e.Other = appendUnique(e.Other, code)
+			}
+		}
+	}
+
+	for _, code := range e.SurveillanceCodes {
+		if code == "N" {
+			if len(e.SurveillanceCodes) > 1 {
+				return nil, fmt.Errorf("item 10b 'N' combined with other equipment: %w", ErrorItemInvalid)
+			}
+			continue
+		}
+		designator, ok := Item10bDesignators[code]
+		if !ok {
+			return nil, fmt.Errorf("item 10b '%s': %w", code, ErrorItemInvalid)
+		}
+		switch designator.Kind {
+		case CapabilitySSR:
+			if e.SSR != "" {
+				return nil, fmt.Errorf("item 10b '%s': more than one transponder mode: %w", code, ErrorItemInvalid)
+			}
+			e.SSR = code
+		case CapabilityADSB:
+			e.ADSB = append(e.ADSB, code)
+		case CapabilityADSC:
+			e.ADSC = append(e.ADSC, code)
+		}
+	}
+
+	return e, nil
+}
+
+// HasEquipment reports whether the item 10a capability code is present,
+// including the capabilities designated by "S"
+func (e *Equipment) HasEquipment(code string) bool {
+	for _, list := range [][]string{e.COM, e.NAV, e.Approach, e.Other} {
+		for _, c := range list {
+			if c == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasSurveillance reports whether the item 10b capability code is present
+func (e *Equipment) HasSurveillance(code string) bool {
+	for _, list := range [][]string{e.ADSB, e.ADSC} {
+		for _, c := range list {
+			if c == code {
+				return true
+			}
+		}
+	}
+	return e.SSR == code
+}
+
+// hasAnySurveillance reports whether any of the item 10b capability codes is present
+func (e *Equipment) hasAnySurveillance(codes []string) bool {
+	for _, code := range codes {
+		if e.HasSurveillance(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// pbnSensors lists the item 10a capabilities required by PBN/ codes
+var pbnSensors = map[string][]string{
+	"A1": {"G"},
+	"B1": {"G", "D"},
+	"B2": {"G"},
+	"B3": {"D"},
+	"B4": {"O", "D"},
+	"B5": {"I"},
+	"C1": {"G", "D"},
+	"C2": {"G"},
+	"C3": {"D"},
+	"C4": {"D", "I"},
+	"D1": {"G", "D"},
+	"D2": {"G"},
+	"D3": {"D"},
+	"D4": {"D", "I"},
+	"L1": {"G"},
+	"O1": {"G", "D"},
+	"O2": {"G"},
+	"O3": {"D"},
+	"O4": {"D", "I"},
+	"S1": {"G"},
+	"S2": {"G"},
+	"T1": {"G"},
+	"T2": {"G"},
+}
+
+// surADSB lists the SUR/ codes declaring ADS-B compliance and the item 10b
+// capabilities of which one is required. Extended squitter transponders
+// (E, L) are 1090 MHz ADS-B out.
+var surADSB = []struct {
+	code         string
+	capabilities []string
+}{
+	{"260B", []string{"B1", "B2", "E", "L"}},
+	{"282B", []string{"U1", "U2"}},
+}
+
+// CrossCheck compares the equipment with the item 18 indicators, as returned
+// by ParseItem18, and reports every inconsistency
+func (e *Equipment) CrossCheck(indicators map[string]string) []error {
+	var errs []error
+	mismatch := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrorEquipmentMismatch))
+	}
+
+	pbn := indicators["PBN"]
+	if e.HasEquipment("R") && pbn == "" {
+		mismatch("item 10a 'R' requires PBN/")
+	}
+	if !e.HasEquipment("R") && pbn != "" {
+		mismatch("PBN/ requires item 10a 'R'")
+	}
+	for _, code := range splitCapabilityCodes(strings.ReplaceAll(pbn, " ", "")) {
+		for _, sensor := range pbnSensors[code] {
+			if !e.HasEquipment(sensor) {
+				mismatch("PBN/%s requires item 10a '%s'", code, sensor)
+			}
+		}
+	}
+
+	if e.HasEquipment("Z") && indicators["COM"] == "" && indicators["NAV"] == "" && indicators["DAT"] == "" {
+		mismatch("item 10a 'Z' requires COM/, NAV/ or DAT/")
+	}
+
+	surCodes := strings.Fields(indicators["SUR"])
+	for _, sur := range surADSB {
+		if !slices.Contains(surCodes, sur.code) || e.hasAnySurveillance(sur.capabilities) {
+			continue
+		}
+		mismatch("SUR/%s requires item 10b '%s'", sur.code, strings.Join(sur.capabilities, "', '"))
+	}
+
+	return errs
+}
+
+// EQCST returns the ADEXP EQCST list form ("X/EQ") of the item 10a codes
+func (e *Equipment) EQCST() []interface{} {
+	return capabilityList(e.Codes)
+}
+
+// SURVEQ returns the ADEXP SURVEQ list form ("X/EQ") of the item 10b codes
+func (e *Equipment) SURVEQ() []interface{} {
+	return capabilityList(e.SurveillanceCodes)
+}
+
+// capabilityList returns the ADEXP list form ("X/EQ") of capability codes,
+// leaving out "N" for none
+func capabilityList(codes []string) []interface{} {
+	list := make([]interface{}, 0)
+	for _, code := range codes {
+		if code == "N" {
+			continue
+		}
+		list = append(list, code+"/EQ")
+	}
+	return list
+}
+
+// splitCapabilityCodes splits an item 10 designator string into its codes, e.g. "SE1J2" into S, E1, J2
+func splitCapabilityCodes(s string) []string {
+	var codes []string
+	for i := 0; i < len(s); i++ {
+		if i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+			codes = append(codes, s[i:i+2])
+			i++
+			continue
+		}
+		codes = append(codes, s[i:i+1])
+	}
+	return codes
+}
+
+func appendUnique(list []string, code string) []string {
+	for _, c := range list {
+		if c == code {
+			return list
+		}
+	}
+	return append(list, code)
+}
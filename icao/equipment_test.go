@@ -0,0 +1,150 @@
+package icao
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_ParseEquipment(t *testing.T) {
+	e, err := ParseEquipment("SDE2GRWY/LB1D1")
+	if err != nil {
+		t.Fatalf("ParseEquipment failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(e.Codes, []string{"S", "D", "E2", "G", "R", "W", "Y"}) {
+		t.Errorf("Unexpected Codes: %v", e.Codes)
+	}
+	if !reflect.DeepEqual(e.COM, []string{"V", "E2", "Y"}) {
+		t.Errorf("Unexpected COM: %v", e.COM)
+	}
+	if !reflect.DeepEqual(e.NAV, []string{"O", "D", "G", "R", "W"}) {
+		t.Errorf("Unexpected NAV: %v", e.NAV)
+	}
+	if !reflect.DeepEqual(e.Approach, []string{"L"}) {
+		t.Errorf("Unexpected Approach: %v", e.Approach)
+	}
+	if e.SSR != "L" {
+		t.Errorf("Expected SSR to be L, got %v", e.SSR)
+	}
+	if !reflect.DeepEqual(e.ADSB, []string{"B1"}) || !reflect.DeepEqual(e.ADSC, []string{"D1"}) {
+		t.Errorf("Unexpected ADSB/ADSC: %v/%v", e.ADSB, e.ADSC)
+	}
+
+	for _, code := range []string{"V", "O", "L", "E2", "W"} {
+		if !e.HasEquipment(code) {
+			t.Errorf("Expected item 10a capability %s", code)
+		}
+	}
+	for _, code := range []string{"S", "H", "J1", "C", "B1", "D1"} {
+		if e.HasEquipment(code) {
+			t.Errorf("Did not expect item 10a capability %s", code)
+		}
+	}
+	for _, code := range []string{"L", "B1", "D1"} {
+		if !e.HasSurveillance(code) {
+			t.Errorf("Expected item 10b capability %s", code)
+		}
+	}
+	for _, code := range []string{"S", "D", "E2", "U1"} {
+		if e.HasSurveillance(code) {
+			t.Errorf("Did not expect item 10b capability %s", code)
+		}
+	}
+
+	if eqcst := e.EQCST(); !reflect.DeepEqual(eqcst, []interface{}{"S/EQ", "D/EQ", "E2/EQ", "G/EQ", "R/EQ", "W/EQ", "Y/EQ"}) {
+		t.Errorf("Unexpected EQCST: %v", eqcst)
+	}
+	if surveq := e.SURVEQ(); !reflect.DeepEqual(surveq, []interface{}{"L/EQ", "B1/EQ", "D1/EQ"}) {
+		t.Errorf("Unexpected SURVEQ: %v", surveq)
+	}
+}
+
+func Test_ParseEquipment_None(t *testing.T) {
+	e, err := ParseEquipment("N/N")
+	if err != nil {
+		t.Fatalf("ParseEquipment failed: %v", err)
+	}
+	if len(e.EQCST()) != 0 || len(e.SURVEQ()) != 0 || e.SSR != "" {
+		t.Errorf("Expected no equipment, got %+v", e)
+	}
+}
+
+func Test_ParseEquipment_Errors(t *testing.T) {
+	for _, item10 := range []string{"SRWY", "SQ/C", "NS/C", "S/CS", "S/NC", "S/B9", "J9/C"} {
+		t.Run(item10, func(t *testing.T) {
+			if _, err := ParseEquipment(item10); !errors.Is(err, ErrorItemInvalid) {
+				t.Errorf("Expected ErrorItemInvalid, got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_Equipment_CrossCheck(t *testing.T) {
+	testCases := []struct {
+		name       string
+		item10     string
+		indicators map[string]string
+		mismatches int
+	}{
+		{name: "consistent", item10: "SDGRWY/S", indicators: map[string]string{"PBN": "B1D1"}, mismatches: 0},
+		{name: "R without PBN", item10: "SRWY/C", indicators: map[string]string{}, mismatches: 1},
+		{name: "PBN without R", item10: "SDGW/S", indicators: map[string]string{"PBN": "B2"}, mismatches: 1},
+		{name: "PBN missing sensors", item10: "SR/S", indicators: map[string]string{"PBN": "D1"}, mismatches: 2},
+		{name: "Z without indicator", item10: "SZ/S", indicators: map[string]string{}, mismatches: 1},
+		{name: "Z with NAV", item10: "SZ/S", indicators: map[string]string{"NAV": "RNVD1E2A1"}, mismatches: 0},
+		{name: "SUR ADS-B with B1", item10: "S/SB1", indicators: map[string]string{"SUR": "260B RSP180"}, mismatches: 0},
+		{name: "SUR 260B without ADS-B", item10: "S/S", indicators: map[string]string{"SUR": "260B"}, mismatches: 1},
+		{name: "SUR 282B with 1090 MHz ADS-B", item10: "S/SB2", indicators: map[string]string{"SUR": "282B"}, mismatches: 1},
+		{name: "SUR 282B with UAT", item10: "S/CU1", indicators: map[string]string{"SUR": "282B"}, mismatches: 0},
+		{name: "SUR 260B with extended squitter", item10: "S/E", indicators: map[string]string{"SUR": "260B"}, mismatches: 0},
+		{name: "SUR 260B with extended squitter and enhanced surveillance", item10: "S/L", indicators: map[string]string{"SUR": "RSP180 260B"}, mismatches: 0},
+		{name: "SUR 260B with item 10a E1", item10: "SE1/S", indicators: map[string]string{"SUR": "260B"}, mismatches: 1},
+		{name: "PBN inertial sensor from item 10b", item10: "SR/I", indicators: map[string]string{"PBN": "B5"}, mismatches: 1},
+		{name: "PBN DME from item 10b", item10: "SR/SD1", indicators: map[string]string{"PBN": "B3"}, mismatches: 1},
+		{name: "SUR without ADS-B code", item10: "S/C", indicators: map[string]string{"SUR": "RSP180"}, mismatches: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := ParseEquipment(tc.item10)
+			if err != nil {
+				t.Fatalf("ParseEquipment failed: %v", err)
+			}
+			errs := e.CrossCheck(tc.indicators)
+			if len(errs) != tc.mismatches {
+				t.Errorf("Expected %d mismatches, got %v", tc.mismatches, errs)
+			}
+			for _, err := range errs {
+				if !errors.Is(err, ErrorEquipmentMismatch) {
+					t.Errorf("Expected ErrorEquipmentMismatch, got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func Test_Parse_CheckEquipment(t *testing.T) {
+	parser := NewParser(ParserOpts{CheckEquipment: true})
+
+	_, err := parser.Parse("(FPL-ABC123-IN-F100/M-SRWY/C-LPPR0600-N0422F340 TURON-LFPG0155-DOF/060110)")
+	var itemErr *ItemError
+	if !errors.As(err, &itemErr) || itemErr.Item != 10 || !errors.Is(err, ErrorEquipmentMismatch) {
+		t.Errorf("Expected an item 10 equipment mismatch, got: %v", err)
+	}
+
+	_, err = parser.Parse("(FPL-ABC123-IN-F100/M-SDGRWY/C-LPPR0600-N0422F340 TURON-LFPG0155-PBN/B1 DOF/060110)")
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	_, err = parser.Parse("(FPL-ABC123-IN-F100/M-SDGRWY/C-LPPR0600-N0422F340 TURON-LFPG0155-PBN/B1 SUR/260B DOF/060110)")
+	if !errors.As(err, &itemErr) || itemErr.Item != 10 || !errors.Is(err, ErrorEquipmentMismatch) {
+		t.Errorf("Expected a SUR/ equipment mismatch, got: %v", err)
+	}
+
+	_, err = parser.Parse("(FPL-ABC123-IN-F100/M-SDGRWY/E-LPPR0600-N0422F340 TURON-LFPG0155-PBN/B1 SUR/RSP180 260B DOF/060110)")
+	if err != nil {
+		t.Errorf("Expected SUR/260B to match extended squitter, got: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package icao
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Item18 holds the decoded other information of item 18
+type Item18 struct {
+	// Indicators maps each indicator present to its value. Repeated
+	// indicators other than EET are joined with a space, a repeated DOF is
+	// rejected with ErrorRepeatedIndicator.
+	Indicators map[string]string
+	// EET holds the estimated elapsed times to points or FIR boundaries
+	EET []EET
+	// DOF is the date of flight, the zero time if DOF/ is not given
+	DOF time.Time
+}
+
+// EET is a single entry of the EET indicator, e.g. EDUU0035
+type EET struct {
+	Location string
+	Elapsed  time.Duration
+}
+
+var ErrorUnknownIndicator = errors.New("unknown indicator")
+var ErrorRepeatedIndicator = errors.New("repeated indicator")
+
+var eetPattern = regexp.MustCompile(`^(.+?)([0-9]{2})([0-5][0-9])$`)
+
+// isItem18Indicator reports whether s is one of the indicators defined for item 18
+func isItem18Indicator(s string) bool {
+	for _, indicator := range Item18Indicators {
+		if indicator == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseItem18 parses the text of item 18. Only the ICAO indicators start a
+// new element, so values containing a slash such as "RMK/TCAS N/A" are kept
+// whole. "0" stands for no information.
+func ParseItem18(s string) (*Item18, error) {
+	item := &Item18{Indicators: make(map[string]string)}
+	s = strings.TrimSpace(s)
+	if s == "0" || s == "" {
+		return item, nil
+	}
+
+	var current string
+	var values []string
+	flush := func() error {
+		if current == "" {
+			return nil
+		}
+		value := strings.Join(values, " ")
+		if err := item.add(current, value); err != nil {
+			return err
+		}
+		values = values[:0]
+		return nil
+	}
+
+	for _, token := range strings.Fields(s) {
+		if pos := strings.Index(token, "/"); pos != -1 && isItem18Indicator(token[:pos]) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			current = token[:pos]
+			token = token[pos+1:]
+		} else if current == "" {
+			return nil, fmt.Errorf("'%s': %w", token, ErrorUnknownIndicator)
+		}
+		if token != "" {
+			values = append(values, token)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// add stores the value of a single indicator
+func (i *Item18) add(indicator string, value string) error {
+	switch indicator {
+	case "EET":
+		for _, entry := range strings.Fields(value) {
+			m := eetPattern.FindStringSubmatch(entry)
+			if m == nil {
+				return fmt.Errorf("EET/%s: %w", entry, ErrorItemInvalid)
+			}
+			hours, _ := strconv.Atoi(m[2])
+			minutes, _ := strconv.Atoi(m[3])
+			i.EET = append(i.EET, EET{Location: m[1], Elapsed: time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute})
+		}
+	case "DOF":
+		// The date of flight is a single date, unlike the indicators
+		// whose values are joined
+		if !i.DOF.IsZero() {
+			return fmt.Errorf("DOF/%s: %w", value, ErrorRepeatedIndicator)
+		}
+		dof, err := time.Parse("060102", value)
+		if err != nil {
+			return fmt.Errorf("DOF/%s: %w", value, ErrorItemInvalid)
+		}
+		i.DOF = dof
+	}
+
+	if v, ok := i.Indicators[indicator]; ok && v != "" {
+		value = v + " " + value
+	}
+	i.Indicators[indicator] = value
+	return nil
+}
+
+// String returns the entry in item 18 format, e.g. EDUU0035
+func (e EET) String() string {
+	minutes := int(e.Elapsed / time.Minute)
+	return fmt.Sprintf("%s%02d%02d", e.Location, minutes/60, minutes%60)
+}
@@ -0,0 +1,85 @@
+package icao
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_ParseItem18(t *testing.T) {
+	item, err := ParseItem18("PBN/A1B1 DOF/240228 EET/EDUU0035 EDVV0102 REG/DESEL RMK/TCAS N/A EET/LOWW0110 RMK/SECOND REMARK STS/HOSP")
+	if err != nil {
+		t.Fatalf("ParseItem18 failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"PBN": "A1B1",
+		"DOF": "240228",
+		"EET": "EDUU0035 EDVV0102 LOWW0110",
+		"REG": "DESEL",
+		"RMK": "TCAS N/A SECOND REMARK",
+		"STS": "HOSP",
+	}
+	if len(item.Indicators) != len(expected) {
+		t.Errorf("Expected %d indicators, got %v", len(expected), item.Indicators)
+	}
+	for indicator, value := range expected {
+		if item.Indicators[indicator] != value {
+			t.Errorf("Expected %s to be '%s', got '%s'", indicator, value, item.Indicators[indicator])
+		}
+	}
+
+	if !item.DOF.Equal(time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected DOF to be 2024-02-28, got %v", item.DOF)
+	}
+
+	expectedEET := []EET{
+		{Location: "EDUU", Elapsed: 35 * time.Minute},
+		{Location: "EDVV", Elapsed: time.Hour + 2*time.Minute},
+		{Location: "LOWW", Elapsed: time.Hour + 10*time.Minute},
+	}
+	if len(item.EET) != len(expectedEET) {
+		t.Fatalf("Expected %d EET entries, got %v", len(expectedEET), item.EET)
+	}
+	for i, eet := range expectedEET {
+		if item.EET[i] != eet {
+			t.Errorf("Expected EET %d to be %v, got %v", i, eet, item.EET[i])
+		}
+		if item.EET[i].String() != expected["EET"][i*9:i*9+8] {
+			t.Errorf("Expected EET %d to format as %s, got %s", i, expected["EET"][i*9:i*9+8], item.EET[i].String())
+		}
+	}
+}
+
+func Test_ParseItem18_Empty(t *testing.T) {
+	item, err := ParseItem18("0")
+	if err != nil {
+		t.Fatalf("ParseItem18 failed: %v", err)
+	}
+	if len(item.Indicators) != 0 || !item.DOF.IsZero() {
+		t.Errorf("Expected no information, got %+v", item)
+	}
+}
+
+func Test_ParseItem18_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected error
+	}{
+		{name: "unknown indicator", value: "XYZ/ABC DOF/240228", expected: ErrorUnknownIndicator},
+		{name: "text before indicator", value: "HELLO RMK/ABC", expected: ErrorUnknownIndicator},
+		{name: "invalid DOF", value: "DOF/241328", expected: ErrorItemInvalid},
+		{name: "repeated DOF", value: "DOF/240228 RMK/ABC DOF/240229", expected: ErrorRepeatedIndicator},
+		{name: "invalid EET", value: "EET/EDUU35", expected: ErrorItemInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseItem18(tc.value)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
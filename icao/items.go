@@ -0,0 +1,242 @@
+package icao
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/davidkohl/goflightplan/adexp"
+)
+
+// ItemError reports a malformed or missing item of an ICAO message
+type ItemError struct {
+	Item  int
+	Value string
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("item %d: %v", e.Item, e.Err)
+	}
+	return fmt.Sprintf("item %d '%s': %v", e.Item, e.Value, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+var ErrorItemMissing = errors.New("item missing")
+var ErrorItemInvalid = errors.New("invalid format")
+var ErrorNoClosingBracket = errors.New("closing bracket missing")
+var ErrorUnsupportedMessage = errors.New("unsupported message type")
+
+var (
+	item7Pattern  = regexp.MustCompile(`^([A-Z0-9]{1,7})(?:/([ABC]?[0-7]{4}))?$`)
+	item8Pattern  = regexp.MustCompile(`^([IVYZ])([SNGMX])?$`)
+	item9Pattern  = regexp.MustCompile(`^([0-9]{1,2})?([A-Z][A-Z0-9]{1,3})/([LMHJ])$`)
+	item10Pattern = regexp.MustCompile(`^([A-Z0-9]+)/([A-Z0-9]*)$`)
+	item13Pattern = regexp.MustCompile(`^([A-Z]{4})([0-9]{4})$`)
+	item16Pattern = regexp.MustCompile(`^([A-Z]{4})([0-9]{4})(?: ([A-Z]{4}))?(?: ([A-Z]{4}))?$`)
+	item17Pattern = regexp.MustCompile(`^([A-Z]{4})([0-9]{4})(?: (.+))?$`)
+	item18Pattern = regexp.MustCompile(`^(0|[A-Z]{3,4}/.*)$`)
+	item19Pattern = regexp.MustCompile(`^[EPRSJDANC]/`)
+	aerodrome     = regexp.MustCompile(`^[A-Z]{4}$`)
+	amendment     = regexp.MustCompile(`^([0-9]{1,2})/(.*)$`)
+)
+
+// messageItems splits the content of a message between the brackets into its
+// dash-delimited items, with line breaks and repeated spaces collapsed
+func messageItems(s string) []string {
+	items := strings.Split(s, "-")
+	for i, item := range items {
+		items[i] = strings.Join(strings.Fields(item), " ")
+	}
+	return items
+}
+
+// itemAt returns the item at position i, or a missing item error
+func itemAt(items []string, i int, item int) (string, error) {
+	if i >= len(items) || items[i] == "" {
+		return "", &ItemError{Item: item, Err: ErrorItemMissing}
+	}
+	return items[i], nil
+}
+
+// Item 7: aircraft identification and SSR mode and code
+func parseItem7(fpl map[string]interface{}, s string) error {
+	m := item7Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return &ItemError{Item: 7, Value: s, Err: ErrorItemInvalid}
+	}
+	fpl["ARCID"] = m[1]
+	if m[2] != "" {
+		fpl["SSRCODE"] = m[2]
+	}
+	return nil
+}
+
+// Item 8: flight rules and type of flight
+func parseItem8(fpl map[string]interface{}, s string) error {
+	m := item8Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return &ItemError{Item: 8, Value: s, Err: ErrorItemInvalid}
+	}
+	fpl["FLTRUL"] = m[1]
+	if m[2] != "" {
+		fpl["FLTTYP"] = m[2]
+	}
+	return nil
+}
+
+// Item 9: number and type of aircraft and wake turbulence category
+func parseItem9(fpl map[string]interface{}, s string) error {
+	m := item9Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return &ItemError{Item: 9, Value: s, Err: ErrorItemInvalid}
+	}
+	nbarc := m[1]
+	if nbarc == "" {
+		nbarc = "1"
+	}
+	fpl["NBARC"] = nbarc
+	fpl["ARCTYP"] = m[2]
+	fpl["WKTRC"] = m[3]
+	return nil
+}
+
+// Item 10: equipment and capabilities, stored as filed under CEQPT and SEQPT
+// and as the ADEXP EQCST and SURVEQ lists
+func parseItem10(fpl map[string]interface{}, s string) error {
+	equipment, err := ParseEquipment(s)
+	if err != nil {
+		return &ItemError{Item: 10, Value: s, Err: err}
+	}
+	ceqpt, seqpt, _ := strings.Cut(s, "/")
+	fpl["CEQPT"] = ceqpt
+	fpl["SEQPT"] = seqpt
+	fpl["EQCST"] = equipment.EQCST()
+	fpl["SURVEQ"] = equipment.SURVEQ()
+	return nil
+}
+
+// Item 13: departure aerodrome and time, stored under timeKey
+func parseItem13(fpl map[string]interface{}, s string, timeKey string) error {
+	m := item13Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return &ItemError{Item: 13, Value: s, Err: ErrorItemInvalid}
+	}
+	fpl["ADEP"] = m[1]
+	fpl[timeKey] = m[2]
+	return nil
+}
+
+// Item 15: route, starting with the initial cruising speed and level. The
+// significant points of the route are stored as RTEPTS.
+func parseItem15(fpl map[string]interface{}, s string) error {
+	elements, err := ParseRoute(s)
+	if err != nil {
+		return &ItemError{Item: 15, Value: s, Err: err}
+	}
+	fpl["ROUTE"] = s
+	fpl["RTEPTS"] = RoutePoints(elements)
+	return nil
+}
+
+// Item 16: destination aerodrome, total EET and up to two alternates
+func parseItem16(fpl map[string]interface{}, s string) error {
+	m := item16Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return &ItemError{Item: 16, Value: s, Err: ErrorItemInvalid}
+	}
+	fpl["ADES"] = m[1]
+	fpl["EELT"] = m[2]
+	if m[3] != "" {
+		fpl["ALTRNT1"] = m[3]
+	}
+	if m[4] != "" {
+		fpl["ALTRNT2"] = m[4]
+	}
+	return nil
+}
+
+// parseDestination parses item 16 as used by messages other than FPL, where
+// only the destination aerodrome is given
+func parseDestination(fpl map[string]interface{}, s string) error {
+	if !aerodrome.MatchString(s) {
+		return &ItemError{Item: 16, Value: s, Err: ErrorItemInvalid}
+	}
+	fpl["ADES"] = s
+	return nil
+}
+
+// Item 17: arrival aerodrome, time of arrival and optional aerodrome name
+func parseItem17(fpl map[string]interface{}, s string) error {
+	m := item17Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return &ItemError{Item: 17, Value: s, Err: ErrorItemInvalid}
+	}
+	fpl["ADARR"] = m[1]
+	fpl["ATA"] = m[2]
+	if m[3] != "" {
+		fpl["ADARRZ"] = m[3]
+	}
+	return nil
+}
+
+// Item 18: other information, "0" if there is none. Indicators are stored
+// under their name, EET as a list of entries and DOF as an adexp.Date.
+func parseItem18(fpl map[string]interface{}, s string) error {
+	if !item18Pattern.MatchString(s) {
+		return &ItemError{Item: 18, Value: s, Err: ErrorItemInvalid}
+	}
+	item18, err := ParseItem18(s)
+	if err != nil {
+		return &ItemError{Item: 18, Value: s, Err: err}
+	}
+	for indicator, value := range item18.Indicators {
+		fpl[indicator] = value
+	}
+	if len(item18.EET) > 0 {
+		eet := make([]interface{}, 0, len(item18.EET))
+		for _, entry := range item18.EET {
+			eet = append(eet, entry.String())
+		}
+		fpl["EET"] = eet
+	}
+	if !item18.DOF.IsZero() {
+		fpl["DOF"] = adexp.Date{Time: item18.DOF}
+	}
+	return nil
+}
+
+// Item 19: supplementary information, stored under SUPPINFO by indicator
+func parseItem19(fpl map[string]interface{}, s string) error {
+	if !item19Pattern.MatchString(s) {
+		return &ItemError{Item: 19, Value: s, Err: ErrorItemInvalid}
+	}
+	suppinfo := make(map[string]interface{})
+	var current string
+	for _, token := range strings.Fields(s) {
+		if item19Pattern.MatchString(token) {
+			current = token[:1]
+			token = token[2:]
+			if token == "" {
+				continue
+			}
+		}
+		if v, ok := suppinfo[current].(string); ok && v != "" {
+			token = v + " " + token
+		}
+		suppinfo[current] = token
+	}
+	fpl["SUPPINFO"] = suppinfo
+	return nil
+}
+
+// isItem19 reports whether s is item 19 rather than item 18, which may be
+// left out of older messages
+func isItem19(s string) bool {
+	return item19Pattern.MatchString(s)
+}
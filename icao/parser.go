@@ -1,12 +1,13 @@
 package icao
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"regexp"
 	"strings"
+
+	"github.com/davidkohl/goflightplan/aftn"
 )
 
 type ParseHandler struct {
@@ -23,6 +24,9 @@ type ParserOpts struct {
 	CreateMessageSets bool
 	AFTNHeader        bool
 	ParseHandler      map[string]ParseHandler
+	// CheckEquipment rejects flight plans whose item 10 does not match the
+	// PBN/, COM/, NAV/, DAT/ and SUR/ indicators of item 18
+	CheckEquipment bool
 }
 
 func NewParser(opts ParserOpts) *ICAOParser {
@@ -44,256 +48,299 @@ func NewParser(opts ParserOpts) *ICAOParser {
 
 // ParseFPLMessage parses an ICAO FPL message and returns a structured FPLMessage.
 func (p *ICAOParser) Parse(s string) (map[string]interface{}, error) {
-	var fpl map[string]interface{} = make(map[string]interface{})
-	var send, rec string
+	var env *aftn.Envelope
 	//if AFTNHeader is true, try to extract it
 	if p.ParserOpts.AFTNHeader {
-		delimStart := "ZCZC"
-
-		//delimEnd := "NNNN"
-
-		i := strings.Index(s, delimStart)
-		if i != -1 {
-			s := s[i:]
-			tmp := strings.Fields(s)
-			rec = tmp[3]
-			send = tmp[5]
-		} else {
+		var text string
+		var err error
+		env, text, err = aftn.Parse(s)
+		switch {
+		case errors.Is(err, aftn.ErrorNoStartOfMessage):
 			log.Println("AFTNHeader Expected but not found")
+		case err != nil:
+			return nil, err
+		default:
+			s = text
 		}
-
 	}
 
 	t, err := getTitle(s)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("TITLE:", t)
+	// The message ends at the last closing bracket, brackets within the
+	// items are left to the item parsers to reject
 	start := strings.Index(s, "(")
-	end := strings.Index(s, ")")
-	fmt.Println("START", start, "END:", end)
-	if start == -1 || end == -1 {
-		return nil, errors.New("could not get start or end of ICAO message")
-	}
-	s = s[start : end+1]
-	// Remove the prefix "(FPL-" and the trailing parenthesis ")"
-	s = strings.TrimPrefix(s, "(")
-	s = strings.TrimSuffix(s, ")")
-
-	s = strings.ReplaceAll(s, "\n", "")
-	for _, v := range p.ParseHandlers {
-		if end == -1 {
-			return nil, errors.New("malformed message: missing )")
-		}
-		if v.Name == t {
-			tmpfpl, err := v.Fn(s)
-			if err != nil {
-				return nil, err
-			}
-			d, err := json.Marshal(tmpfpl)
-			if err != nil {
-				return nil, err
-			}
-
-			err = json.Unmarshal(d, &fpl)
-			if err != nil {
-				return nil, err
-			}
+	end := strings.LastIndex(s[start+1:], ")")
+	if end == -1 {
+		return nil, &ItemError{Item: 3, Err: ErrorNoClosingBracket}
+	}
+	s = s[start+1 : start+1+end]
+
+	handler, ok := p.ParseHandlers[t]
+	if !ok {
+		return nil, &ItemError{Item: 3, Value: t, Err: ErrorUnsupportedMessage}
+	}
+	fpl, err := handler.Fn(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.ParserOpts.CheckEquipment {
+		if err := CheckEquipment(fpl); err != nil {
+			return nil, err
 		}
 	}
 
-	if p.ParserOpts.AFTNHeader {
-		fpl["REFDATA"].(map[string]interface{})["SENDER"].(map[string]interface{})["FAC"] = send
-		fpl["REFDATA"].(map[string]interface{})["RECVR"].(map[string]interface{})["FAC"] = rec
+	if env != nil {
+		setRefData(fpl, env)
 	}
 
 	return fpl, nil
 }
 
+// parseFPL parses a filed flight plan message (FPL-7-8-9-10-13-15-16-18-19)
 func parseFPL(s string) (map[string]interface{}, error) {
 	var fpl = make(map[string]interface{}, 0)
+	items := messageItems(s)
+	fpl["TITLE"] = "FPL"
 
-	// Split the fields using "-" as the delimiter
-	fields := strings.Split(s, "-")
-
-	if len(fields) < 7 {
-		return nil, errors.New("incomplete FPL message")
+	// Items 7 to 16 are mandatory and identified by their position
+	parsers := []struct {
+		item int
+		fn   func(map[string]interface{}, string) error
+	}{
+		{7, parseItem7},
+		{8, parseItem8},
+		{9, parseItem9},
+		{10, parseItem10},
+		{13, func(fpl map[string]interface{}, s string) error { return parseItem13(fpl, s, "EOBT") }},
+		{15, parseItem15},
+		{16, parseItem16},
 	}
-	fmt.Println(fields)
-
-	// Split the aircraft type and wake turbulence category
-	aircraftParts := strings.Split(fields[3], "/")
-	if len(aircraftParts) != 2 {
-		return nil, errors.New("invalid aircraft type or wake turbulence category format")
+	for i, parser := range parsers {
+		item, err := itemAt(items, i+1, parser.item)
+		if err != nil {
+			return nil, err
+		}
+		if err := parser.fn(fpl, item); err != nil {
+			return nil, err
+		}
 	}
 
-	// Extract the departure aerodrome and estimated off-block time (EOBT)
-	departureInfo := fields[5]
-
-	// Extract route, destination aerodrome, and estimated elapsed time
-	route := fields[6]
-	destinationInfo := fields[7]
-	if len(destinationInfo) < 7 {
-		return nil, errors.New("destination information is too short")
+	// Item 18 and 19 are recognised by their content, as older messages may
+	// leave out item 18
+	rest := items[len(parsers)+1:]
+	if len(rest) > 0 && !isItem19(rest[0]) {
+		if err := parseItem18(fpl, rest[0]); err != nil {
+			return nil, err
+		}
+		rest = rest[1:]
 	}
-
-	// Ensure EELT is assigned correctly if present
-	eelt := ""
-	_ = eelt
-	if len(destinationInfo) > 4 {
-		eelt = destinationInfo[4:]
+	if len(rest) > 0 {
+		if err := parseItem19(fpl, rest[0]); err != nil {
+			return nil, err
+		}
+		rest = rest[1:]
 	}
-
-	// Extract and assign values to the FPLMessage struct
-
-	fpl["TITLE"] = "FPL"
-	fpl["ARCID"] = fields[0]             // Aircraft ID
-	fpl["FLTRUL"] = string(fields[1][0]) // Flight rules
-	fpl["FLTTYP"] = string(fields[1][1]) // Flight type
-	fpl["ARCTYP"] = aircraftParts[0]     // Aircraft type
-	fpl["WKTRC"] = aircraftParts[1]      // Wake turbulence category
-	fpl["ADEP"] = departureInfo[:4]      // Departure aerodrome
-	fpl["EOBT"] = departureInfo[4:]      // Estimated Off-Block Time (departure time)
-	fpl["ROUTE"] = route                 // Route
-	fpl["ADES"] = destinationInfo[:4]    // Destination aerodrome
-	fpl["EELT"] = eelt                   // Estimated Elapsed Time
-
-	if err := parseField18(&fpl, fields[8]); err != nil {
-		return nil, err
+	if len(rest) > 0 {
+		return nil, &ItemError{Item: 19, Value: rest[0], Err: errors.New("unexpected item after item 19")}
 	}
+
 	return fpl, nil
 }
 
-// ParseCHGMessage parses a CHG message and updates an existing Flightplan.
+// parseCHG parses a modification message (CHG-7-13-16-18-22...). The amended
+// items are kept as text under AMEND, keyed by item number, and are applied to
+// the flight plan except for items 7, 13 and 16, which identify the flight.
 func parseCHG(s string) (map[string]interface{}, error) {
+	fpl, items, err := parseReference("CHG", s, "EOBT")
+	if err != nil {
+		return nil, err
+	}
 
-	var fpl map[string]interface{} = make(map[string]interface{})
-
-	// Remove the prefix and split the message into parts.
-
-	parts := strings.Split(s, "-")
-
-	if len(parts) < 2 {
-		return nil, errors.New("incomplete CHG message")
-	}
-
-	// The first part after CHG should be the Aircraft ID.]\
-	fpl["TITLE"] = "CHG"
-	fpl["ARCID"] = parts[1]
-
-	// Iterate over the remaining parts to handle changes.
-	for i := 2; i < len(parts); i++ {
-		part := parts[i]
-		if pos := strings.Index(part, "/"); pos != -1 {
-			fieldNum := part[:pos]
-			newValue := part[pos+1:]
-			switch fieldNum {
-			case "3":
-				fpl["ARCTYP"] = newValue
-			case "8":
-				fpl["ADEP"] = newValue
-			case "13":
-				fpl["ADES"] = newValue
-				if len(newValue) > 4 {
-					fpl["ADES"] = newValue[:4]
-					fpl["EELT"] = newValue[4:]
-				}
-			case "15":
-				fpl["ROUTE"] = newValue
-			case "16":
-				fpl["ELDT"] = newValue // Assuming ELDT is relevant here
-			case "18":
-				if err := parseField18(&fpl, newValue); err != nil {
-				}
-			}
+	amend := make(map[string]interface{})
+	for _, item := range items {
+		m := amendment.FindStringSubmatch(item)
+		if m == nil {
+			return nil, &ItemError{Item: 22, Value: item, Err: ErrorItemInvalid}
+		}
+		amend[m[1]] = m[2]
+
+		var err error
+		switch m[1] {
+		case "8":
+			err = parseItem8(fpl, m[2])
+		case "9":
+			err = parseItem9(fpl, m[2])
+		case "10":
+			err = parseItem10(fpl, m[2])
+		case "15":
+			err = parseItem15(fpl, m[2])
+		case "18":
+			err = parseItem18(fpl, m[2])
+		case "19":
+			err = parseItem19(fpl, m[2])
+		}
+		if err != nil {
+			return nil, &ItemError{Item: 22, Value: item, Err: err}
 		}
 	}
+	if len(amend) == 0 {
+		return nil, &ItemError{Item: 22, Err: ErrorItemMissing}
+	}
+	fpl["AMEND"] = amend
 
 	return fpl, nil
 }
-func parseCNL(s string) (map[string]interface{}, error) {
-	var fpl = make(map[string]interface{})
-	s = strings.TrimPrefix(s, "(CHG-")
-	parts := strings.Split(s, "-")
-	// (CNL-WMT912-EDJA2010-LIRF-DOF/240228)
-	fpl["TITLE"] = "CNL"
-	fpl["ARCID"] = parts[1]
-	fpl["ADEP"] = parts[2][:4]
-	fpl["EOBT"] = parts[2][4:]
-	fpl["ADES"] = parts[3]
-	fpl["DOF"] = strings.Split(parts[4], "/")[1]
 
+// parseCNL parses a cancellation message (CNL-7-13-16-18)
+func parseCNL(s string) (map[string]interface{}, error) {
+	fpl, items, err := parseReference("CNL", s, "EOBT")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		return nil, &ItemError{Item: 18, Value: items[0], Err: errors.New("unexpected item after item 18")}
+	}
 	return fpl, nil
 }
 
+// parseDLA parses a delay message (DLA-7-13-16-18), item 13 holds the new EOBT
 func parseDLA(s string) (map[string]interface{}, error) {
-	var fpl = make(map[string]interface{})
-	s = strings.TrimPrefix(s, "(CHG-")
-	parts := strings.Split(s, "-")
-	// (DLA-WZZ5322-LYNI1025-EDJA-DOF/240228)
-	fpl["TITLE"] = "DLA"
-	fpl["ARCID"] = parts[1]
-	fpl["ADEP"] = parts[2][:4]
-	fpl["EOBT"] = parts[2][4:]
-	fpl["ADES"] = parts[3]
-	fpl["DOF"] = strings.Split(parts[4], "/")[1]
+	fpl, items, err := parseReference("DLA", s, "EOBT")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		return nil, &ItemError{Item: 18, Value: items[0], Err: errors.New("unexpected item after item 18")}
+	}
 	return fpl, nil
 }
+
+// parseDEP parses a departure message (DEP-7-13-16-18), item 13 holds the
+// actual time of departure
+func parseDEP(s string) (map[string]interface{}, error) {
+	fpl, items, err := parseReference("DEP", s, "ATD")
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		return nil, &ItemError{Item: 18, Value: items[0], Err: errors.New("unexpected item after item 18")}
+	}
+	return fpl, nil
+}
+
+// parseARR parses an arrival message (ARR-7-13-16-17). Item 16 is only
+// present if the flight landed at an aerodrome other than its destination.
 func parseARR(s string) (map[string]interface{}, error) {
 	var fpl = make(map[string]interface{})
-	s = strings.TrimPrefix(s, "(CHG-")
-	parts := strings.Split(s, "-")
-	//(ARR-WZZ301-EDJA0910-BKPR1048)
+	items := messageItems(s)
 	fpl["TITLE"] = "ARR"
-	fpl["ARCID"] = parts[1]
-	fpl["ARCID"] = parts[2][:4]
-	fpl["EOBT"] = parts[2][4:]
-	fpl["ADES"] = parts[len(parts)-1][:4]
-	fpl["ELDT"] = parts[len(parts)-1][4:]
 
-	// if len parts > 4, parts[3] is the stop inbetween
+	item7, err := itemAt(items, 1, 7)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseItem7(fpl, item7); err != nil {
+		return nil, err
+	}
+	item13, err := itemAt(items, 2, 13)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseItem13(fpl, item13, "EOBT"); err != nil {
+		return nil, err
+	}
+
+	switch len(items) {
+	case 4:
+	case 5:
+		if err := parseDestination(fpl, items[3]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ItemError{Item: 17, Err: ErrorItemMissing}
+	}
+	if err := parseItem17(fpl, items[len(items)-1]); err != nil {
+		return nil, err
+	}
 	return fpl, nil
 }
 
-func parseDEP(s string) (map[string]interface{}, error) {
+// parseReference parses items 7, 13, 16 and the optional item 18 which
+// identify the flight in CHG, CNL, DLA and DEP messages. It returns the
+// remaining items.
+func parseReference(title string, s string, timeKey string) (map[string]interface{}, []string, error) {
 	var fpl = make(map[string]interface{})
-	s = strings.TrimPrefix(s, "(CHG-")
-	parts := strings.Split(s, "-")
-	//(DEP-WZZ456-BKPR1155-EDJA-DOF/240228)
-	fpl["TITLE"] = "DEP"
-	fpl["ARCID"] = parts[1]
-	fpl["ARCID"] = parts[2][:4]
-	fpl["EOBT"] = parts[2][4:]
-	fpl["ADES"] = parts[3][:4]
-	fpl["EDLT"] = parts[3][4:]
-	fpl["DOF"] = strings.Split(parts[4], "/")[1]
-	return fpl, nil
-}
+	items := messageItems(s)
+	fpl["TITLE"] = title
+
+	item7, err := itemAt(items, 1, 7)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := parseItem7(fpl, item7); err != nil {
+		return nil, nil, err
+	}
+	item13, err := itemAt(items, 2, 13)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := parseItem13(fpl, item13, timeKey); err != nil {
+		return nil, nil, err
+	}
+	item16, err := itemAt(items, 3, 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := parseDestination(fpl, item16); err != nil {
+		return nil, nil, err
+	}
 
-// ParseOtherInfo handles the parsing of Field 18 and sets the corresponding fields in the Flightplan.
-func parseField18(fp *map[string]interface{}, otherInfo string) error {
-
-	var currentKey string
-	var currentVal strings.Builder
-
-	tokens := strings.Fields(otherInfo)
-	for _, token := range tokens {
-		if pos := strings.Index(token, "/"); pos != -1 {
-			if currentKey != "" {
-				(*fp)[currentKey] = strings.TrimSpace(currentVal.String())
-				currentVal.Reset()
-			}
-			currentKey = token[:pos]
-			currentVal.WriteString(token[pos+1:] + " ")
-		} else {
-			currentVal.WriteString(token + " ")
+	rest := items[4:]
+	if len(rest) > 0 && !amendment.MatchString(rest[0]) {
+		if err := parseItem18(fpl, rest[0]); err != nil {
+			return nil, nil, err
 		}
+		rest = rest[1:]
 	}
+	return fpl, rest, nil
+}
 
-	if currentKey != "" {
-		(*fp)[currentKey] = strings.TrimSpace(currentVal.String())
+// setRefData stores the AFTN originator and first addressee as the REFDATA
+// sender and receiver facilities, and the filing time as FILTIM
+func setRefData(fpl map[string]interface{}, env *aftn.Envelope) {
+	refdata, ok := fpl["REFDATA"].(map[string]interface{})
+	if !ok {
+		refdata = make(map[string]interface{})
+		fpl["REFDATA"] = refdata
+	}
+	refdata["SENDER"] = map[string]interface{}{"FAC": env.Originator}
+	if len(env.Addressees) > 0 {
+		refdata["RECVR"] = map[string]interface{}{"FAC": env.Addressees[0]}
 	}
+	fpl["FILTIM"] = env.FilingTime
+}
 
+// CheckEquipment cross-checks item 10 of a parsed flight plan with its item 18 indicators
+func CheckEquipment(fpl map[string]interface{}) error {
+	ceqpt, ok := fpl["CEQPT"].(string)
+	if !ok {
+		return nil
+	}
+	item10 := ceqpt + "/" + value(fpl, "SEQPT")
+	equipment, err := ParseEquipment(item10)
+	if err != nil {
+		return &ItemError{Item: 10, Value: item10, Err: err}
+	}
+	indicators := make(map[string]string)
+	for _, indicator := range []string{"PBN", "COM", "NAV", "DAT", "SUR"} {
+		indicators[indicator] = value(fpl, indicator)
+	}
+	if errs := equipment.CrossCheck(indicators); len(errs) > 0 {
+		return &ItemError{Item: 10, Value: item10, Err: errors.Join(errs...)}
+	}
 	return nil
 }
 
@@ -1,11 +1,22 @@
 package icao
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/davidkohl/goflightplan/adexp"
 )
 
+// date returns the decoded date of flight of the YYMMDD text s
+func date(s string) adexp.Date {
+	d, _ := time.Parse("060102", s)
+	return adexp.Date{Time: d}
+}
+
 func Test_Parse(t *testing.T) {
 	// Load the test schema from JSON files
 	parser := NewParser(ParserOpts{AFTNHeader: false})
@@ -38,8 +49,8 @@ func Test_Parse(t *testing.T) {
 				if fpl["ADES"] != "LIRF" {
 					t.Errorf("Expected ADES to be 'LIRF' but got %v\n", fpl["LIRF"])
 				}
-				if fpl["DOF"] != "240228" {
-					t.Errorf("Expected DOF to be '240228' but got %v\n", fpl["DOF"])
+				if fpl["DOF"] != date("240228") {
+					t.Errorf("Expected DOF to be 2024-02-28 but got %v\n", fpl["DOF"])
 				}
 			},
 		},
@@ -64,8 +75,8 @@ func Test_Parse(t *testing.T) {
 					t.Errorf("Expected EELT to be '0155' but got %v\n", fpl["EELT"])
 				}
 				// Field 18
-				if fpl["DOF"] != "060110" {
-					t.Errorf("Expected DOF to be '060110' but got %v\n", fpl["DOF"])
+				if fpl["DOF"] != date("060110") {
+					t.Errorf("Expected DOF to be 2006-01-10 but got %v\n", fpl["DOF"])
 				}
 				if fpl["REG"] != "DESEL" {
 					t.Errorf("Expected DOF to be 'DESEL' but got %v\n", fpl["DOF"])
@@ -73,6 +84,124 @@ func Test_Parse(t *testing.T) {
 				if fpl["RMK"] != "THIS HAS SPACE AT END" {
 					t.Errorf("Expected DOF to be 'DESEL' but got %v\n", fpl["DOF"])
 				}
+				if fpl["ARCID"] != "ABC123" {
+					t.Errorf("Expected ARCID to be 'ABC123' but got %v\n", fpl["ARCID"])
+				}
+				if fpl["FLTRUL"] != "I" || fpl["FLTTYP"] != "N" {
+					t.Errorf("Expected FLTRUL/FLTTYP to be 'I'/'N' but got %v/%v\n", fpl["FLTRUL"], fpl["FLTTYP"])
+				}
+				if fpl["ARCTYP"] != "F100" {
+					t.Errorf("Expected ARCTYP to be 'F100' but got %v\n", fpl["ARCTYP"])
+				}
+				if fpl["CEQPT"] != "SRWY" || fpl["SEQPT"] != "C" {
+					t.Errorf("Expected CEQPT/SEQPT to be 'SRWY'/'C' but got %v/%v\n", fpl["CEQPT"], fpl["SEQPT"])
+				}
+				if fpl["ROUTE"] != "N0422F340 TURON UP600 STG UN741 KEPER" {
+					t.Errorf("Expected ROUTE to be 'N0422F340 TURON UP600 STG UN741 KEPER' but got %v\n", fpl["ROUTE"])
+				}
+				rtepts, ok := fpl["RTEPTS"].([]interface{})
+				if !ok || len(rtepts) != 3 {
+					t.Errorf("Expected 3 route points but got %v\n", fpl["RTEPTS"])
+				}
+			},
+		},
+		{
+			name:     "FPL Full",
+			filename: "FPL_full.txt",
+			description: `
+            This test verifies SSR code, multiple aircraft, alternates and item 19
+            `,
+			expected: func(t *testing.T, fpl map[string]interface{}) {
+				expected := map[string]string{
+					"ARCID":   "NAF21",
+					"SSRCODE": "A5012",
+					"FLTRUL":  "I",
+					"FLTTYP":  "M",
+					"NBARC":   "2",
+					"ARCTYP":  "F16",
+					"WKTRC":   "M",
+					"ADEP":    "EHVK",
+					"EOBT":    "1030",
+					"ADES":    "EDDF",
+					"EELT":    "0125",
+					"ALTRNT1": "EDDM",
+					"ALTRNT2": "EDDS",
+					"PBN":     "B2",
+					"RMK":     "TCAS N/A",
+				}
+				for key, value := range expected {
+					if fpl[key] != value {
+						t.Errorf("Expected %s to be '%s' but got %v\n", key, value, fpl[key])
+					}
+				}
+				if fpl["DOF"] != date("240301") {
+					t.Errorf("Expected DOF to be 2024-03-01 but got %v\n", fpl["DOF"])
+				}
+				if _, exists := fpl["N"]; exists {
+					t.Errorf("Expected N to not be present, but it was")
+				}
+				eet, ok := fpl["EET"].([]interface{})
+				if !ok || len(eet) != 2 || eet[0] != "EHAA0010" || eet[1] != "EDVV0025" {
+					t.Errorf("Expected EET to be [EHAA0010 EDVV0025] but got %v\n", fpl["EET"])
+				}
+				eqcst, ok := fpl["EQCST"].([]interface{})
+				if !ok || len(eqcst) != 4 || eqcst[0] != "S/EQ" || eqcst[3] != "W/EQ" {
+					t.Errorf("Expected EQCST to be [S/EQ D/EQ G/EQ W/EQ] but got %v\n", fpl["EQCST"])
+				}
+				surveq, ok := fpl["SURVEQ"].([]interface{})
+				if !ok || len(surveq) != 2 || surveq[0] != "S/EQ" || surveq[1] != "B1/EQ" {
+					t.Errorf("Expected SURVEQ to be [S/EQ B1/EQ] but got %v\n", fpl["SURVEQ"])
+				}
+				suppinfo, ok := fpl["SUPPINFO"].(map[string]interface{})
+				if !ok {
+					t.Fatalf("Expected SUPPINFO to be a map[string]interface{}")
+				}
+				if suppinfo["E"] != "0230" || suppinfo["D"] != "2 8 C YELLOW" || suppinfo["A"] != "GREY" {
+					t.Errorf("Unexpected SUPPINFO: %v\n", suppinfo)
+				}
+			},
+		},
+		{
+			name:     "DLA",
+			filename: "DLA.txt",
+			expected: func(t *testing.T, fpl map[string]interface{}) {
+				if fpl["TITLE"] != "DLA" || fpl["ARCID"] != "WZZ5322" || fpl["ADEP"] != "LYNI" || fpl["EOBT"] != "1025" || fpl["ADES"] != "EDJA" || fpl["DOF"] != date("240228") {
+					t.Errorf("Unexpected DLA: %v\n", fpl)
+				}
+			},
+		},
+		{
+			name:     "DEP",
+			filename: "DEP.txt",
+			expected: func(t *testing.T, fpl map[string]interface{}) {
+				if fpl["TITLE"] != "DEP" || fpl["ARCID"] != "WZZ456" || fpl["ADEP"] != "BKPR" || fpl["ATD"] != "1155" || fpl["ADES"] != "EDJA" {
+					t.Errorf("Unexpected DEP: %v\n", fpl)
+				}
+			},
+		},
+		{
+			name:     "ARR",
+			filename: "ARR.txt",
+			expected: func(t *testing.T, fpl map[string]interface{}) {
+				if fpl["TITLE"] != "ARR" || fpl["ARCID"] != "WZZ301" || fpl["ADEP"] != "EDJA" || fpl["EOBT"] != "0910" || fpl["ADARR"] != "BKPR" || fpl["ATA"] != "1048" {
+					t.Errorf("Unexpected ARR: %v\n", fpl)
+				}
+			},
+		},
+		{
+			name:     "CHG",
+			filename: "CHG.txt",
+			expected: func(t *testing.T, fpl map[string]interface{}) {
+				if fpl["ARCID"] != "ABC123" || fpl["ADEP"] != "LPPR" || fpl["ADES"] != "LFPG" || fpl["DOF"] != date("060110") {
+					t.Errorf("Unexpected CHG reference: %v\n", fpl)
+				}
+				if fpl["ARCTYP"] != "B738" || fpl["ROUTE"] != "N0420F350 TURON UP600 STG" {
+					t.Errorf("Expected amendments to be applied, got ARCTYP %v ROUTE %v\n", fpl["ARCTYP"], fpl["ROUTE"])
+				}
+				amend, ok := fpl["AMEND"].(map[string]interface{})
+				if !ok || amend["9"] != "B738/M" {
+					t.Errorf("Expected AMEND to contain item 9, got %v\n", fpl["AMEND"])
+				}
 			},
 		},
 	}
@@ -96,6 +225,171 @@ func Test_Parse(t *testing.T) {
 	}
 }
 
+func Test_Parse_AFTNHeader(t *testing.T) {
+	parser := NewParser(ParserOpts{AFTNHeader: true})
+
+	content, err := os.ReadFile("../test/fpl/aftn/FPL.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	fpl, err := parser.Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fpl["ARCID"] != "ABC123" {
+		t.Errorf("Expected ARCID to be 'ABC123' but got %v\n", fpl["ARCID"])
+	}
+	refdata, ok := fpl["REFDATA"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected REFDATA to be a map[string]interface{}")
+	}
+	if sender, _ := refdata["SENDER"].(map[string]interface{}); sender["FAC"] != "LPPRZPZX" {
+		t.Errorf("Expected SENDER.FAC to be 'LPPRZPZX' but got %v\n", refdata["SENDER"])
+	}
+	if recvr, _ := refdata["RECVR"].(map[string]interface{}); recvr["FAC"] != "LFPGZPZX" {
+		t.Errorf("Expected RECVR.FAC to be 'LFPGZPZX' but got %v\n", refdata["RECVR"])
+	}
+	if fpl["FILTIM"] != "151229" {
+		t.Errorf("Expected FILTIM to be '151229' but got %v\n", fpl["FILTIM"])
+	}
+
+	// Messages without an envelope are still parsed
+	fpl, err = parser.Parse("(CNL-WMT912-EDJA2010-LIRF-DOF/240228)")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, exists := fpl["REFDATA"]; exists {
+		t.Errorf("Expected REFDATA to not be present, but it was")
+	}
+
+	// A broken envelope is reported
+	if _, err := parser.Parse("ZCZC ABC001\nGG EDDFZQZX\n(CNL-WMT912-EDJA2010-LIRF-0)"); err == nil {
+		t.Errorf("Expected an error for a missing NNNN, got nil")
+	}
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	parser := NewParser(ParserOpts{AFTNHeader: false})
+
+	testCases := []struct {
+		name     string
+		message  string
+		item     int
+		expected string
+	}{
+		{
+			name:     "invalid item 8",
+			message:  "(FPL-ABC123-QN-F100/M-SRWY/C-LPPR0600-N0422F340 TURON-LFPG0155-0)",
+			item:     8,
+			expected: "item 8 'QN': invalid format",
+		},
+		{
+			name:     "invalid item 9",
+			message:  "(FPL-ABC123-IN-F100-SRWY/C-LPPR0600-N0422F340 TURON-LFPG0155-0)",
+			item:     9,
+			expected: "item 9 'F100': invalid format",
+		},
+		{
+			name:     "missing item 16",
+			message:  "(FPL-ABC123-IN-F100/M-SRWY/C-LPPR0600-N0422F340 TURON)",
+			item:     16,
+			expected: "item 16: item missing",
+		},
+		{
+			name:     "invalid item 15",
+			message:  "(FPL-ABC123-IN-F100/M-SRWY/C-LPPR0600-TURON UP600-LFPG0155-0)",
+			item:     15,
+			expected: "item 15 'TURON UP600': initial speed and level 'TURON': invalid route element",
+		},
+		{
+			name:     "invalid item 13 in CNL",
+			message:  "(CNL-WMT912-EDJA20-LIRF-DOF/240228)",
+			item:     13,
+			expected: "item 13 'EDJA20': invalid format",
+		},
+		{
+			name:     "CHG without amendments",
+			message:  "(CHG-WMT912-EDJA2010-LIRF-DOF/240228)",
+			item:     22,
+			expected: "item 22: item missing",
+		},
+		{
+			name:     "invalid date of flight",
+			message:  "(CNL-WMT912-EDJA2010-LIRF-DOF/241345)",
+			item:     18,
+			expected: "item 18 'DOF/241345': DOF/241345: invalid format",
+		},
+		{
+			name:     "repeated date of flight",
+			message:  "(CNL-WMT912-EDJA2010-LIRF-DOF/240228 DOF/240229)",
+			item:     18,
+			expected: "item 18 'DOF/240228 DOF/240229': DOF/240229: repeated indicator",
+		},
+		{
+			name:     "closing bracket before opening bracket",
+			message:  "x) (FPL-A",
+			item:     3,
+			expected: "item 3: closing bracket missing",
+		},
+		{
+			name:     "closing bracket missing",
+			message:  "(CNL-WMT912-EDJA2010-LIRF-DOF/240228",
+			item:     3,
+			expected: "item 3: closing bracket missing",
+		},
+		{
+			name:     "closing bracket within the items",
+			message:  "(CNL-WMT912-EDJA201)-LIR)-DOF/24",
+			item:     13,
+			expected: "item 13 'EDJA201)': invalid format",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.Parse(tc.message)
+			var itemErr *ItemError
+			if !errors.As(err, &itemErr) {
+				t.Fatalf("Expected an ItemError, got: %v", err)
+			}
+			if itemErr.Item != tc.item {
+				t.Errorf("Expected error for item %d, got item %d", tc.item, itemErr.Item)
+			}
+			if err.Error() != tc.expected {
+				t.Errorf("Expected error '%s', got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func Test_Parse_RoundTrip(t *testing.T) {
+	parser := NewParser(ParserOpts{AFTNHeader: false})
+
+	for _, filename := range []string{"FPL.txt", "FPL_full.txt", "CNL.txt", "DLA.txt", "DEP.txt", "ARR.txt", "CHG.txt"} {
+		t.Run(filename, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("../test/fpl/icao", filename))
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+			expected, err := parser.Parse(string(content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			s, err := Marshal(expected)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			result, err := parser.Parse(s)
+			if err != nil {
+				t.Fatalf("Parse of encoded message failed: %v\n%s", err, s)
+			}
+			if !reflect.DeepEqual(expected, result) {
+				t.Errorf("Round trip mismatch:\nexpected %v\ngot      %v", expected, result)
+			}
+		})
+	}
+}
+
 /*
 fpl["TITLE"] = "FPL"
 	fpl["ARCID"] = fields[0]             // Aircraft ID
@@ -0,0 +1,208 @@
+package icao
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type RouteElementType string
+
+const (
+	RouteSpeedLevel  RouteElementType = "SPEEDLEVEL"
+	RouteATS         RouteElementType = "ATSROUTE"
+	RoutePoint       RouteElementType = "POINT"
+	RouteDirect      RouteElementType = "DCT"
+	RouteSID         RouteElementType = "SID"
+	RouteSTAR        RouteElementType = "STAR"
+	RouteFlightRules RouteElementType = "FLTRUL"
+	RouteCruiseClimb RouteElementType = "CRUISECLIMB"
+	RouteTruncation  RouteElementType = "T"
+)
+
+// RouteElement is a single element of an item 15 route. Speed and Level are
+// set on the initial speed/level element and on points where they change.
+type RouteElement struct {
+	Type  RouteElementType
+	Value string
+	Speed string
+	Level string
+
+	// Lat and Lon are set for points given as coordinates, in decimal degrees
+	Lat float64
+	Lon float64
+	// Bearing and Distance are set for points given as bearing and distance
+	// (in degrees and nautical miles) from Value
+	Bearing  int
+	Distance int
+	// UpperLevel is the upper level of a cruise climb, "PLUS" if the climb
+	// continues above Level
+	UpperLevel string
+}
+
+var ErrorRouteElement = errors.New("invalid route element")
+
+var (
+	speedPattern       = `(?:[KN][0-9]{4}|M[0-9]{3})`
+	levelPattern       = `(?:[FA][0-9]{3}|[SM][0-9]{4}|VFR)`
+	speedLevelPattern  = regexp.MustCompile(`^(` + speedPattern + `)(` + levelPattern + `)$`)
+	atsRoutePattern    = regexp.MustCompile(`^[KUS]?[A-Z][0-9]{1,3}[A-Z]?$`)
+	procedurePattern   = regexp.MustCompile(`^[A-Z]{2,5}[0-9][A-Z]?$`)
+	namedPointPattern  = regexp.MustCompile(`^[A-Z]{2,5}$`)
+	latLongPattern     = regexp.MustCompile(`^([0-9]{2})([0-9]{2})?([NS])([0-9]{3})([0-9]{2})?([EW])$`)
+	bearingDistPattern = regexp.MustCompile(`^(.+?)([0-9]{3})([0-9]{3})$`)
+	cruiseClimbPattern = regexp.MustCompile(`^C/([^/]+)/(` + speedPattern + `)(` + levelPattern + `)(PLUS|` + levelPattern + `)$`)
+	changePointPattern = regexp.MustCompile(`^([^/]+)/(` + speedPattern + `)(` + levelPattern + `)$`)
+	flightRules        = map[string]bool{"VFR": true, "IFR": true}
+)
+
+// ParseRoute parses an item 15 route into its ordered elements. Everything
+// after a truncation indicator "T" is ignored.
+func ParseRoute(s string) ([]RouteElement, error) {
+	tokens := strings.Fields(s)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty route: %w", ErrorRouteElement)
+	}
+
+	m := speedLevelPattern.FindStringSubmatch(tokens[0])
+	if m == nil {
+		return nil, fmt.Errorf("initial speed and level '%s': %w", tokens[0], ErrorRouteElement)
+	}
+	elements := []RouteElement{{Type: RouteSpeedLevel, Value: tokens[0], Speed: m[1], Level: m[2]}}
+
+	for i := 1; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "T" {
+			elements = append(elements, RouteElement{Type: RouteTruncation, Value: token})
+			break
+		}
+
+		element, err := parseRouteElement(token, i == 1, i == len(tokens)-1)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	return elements, nil
+}
+
+// parseRouteElement classifies a single route token. SID and STAR designators
+// are only recognised as the first and last element.
+func parseRouteElement(token string, first bool, last bool) (RouteElement, error) {
+	switch {
+	case token == "DCT":
+		return RouteElement{Type: RouteDirect, Value: token}, nil
+	case flightRules[token]:
+		return RouteElement{Type: RouteFlightRules, Value: token}, nil
+	case strings.HasPrefix(token, "C/"):
+		m := cruiseClimbPattern.FindStringSubmatch(token)
+		if m == nil {
+			return RouteElement{}, fmt.Errorf("cruise climb '%s': %w", token, ErrorRouteElement)
+		}
+		element, err := parsePoint(m[1])
+		if err != nil {
+			return RouteElement{}, err
+		}
+		element.Type = RouteCruiseClimb
+		element.Speed = m[2]
+		element.Level = m[3]
+		element.UpperLevel = m[4]
+		return element, nil
+	case strings.Contains(token, "/"):
+		m := changePointPattern.FindStringSubmatch(token)
+		if m == nil {
+			return RouteElement{}, fmt.Errorf("speed or level change '%s': %w", token, ErrorRouteElement)
+		}
+		element, err := parsePoint(m[1])
+		if err != nil {
+			return RouteElement{}, err
+		}
+		element.Speed = m[2]
+		element.Level = m[3]
+		return element, nil
+	case atsRoutePattern.MatchString(token):
+		return RouteElement{Type: RouteATS, Value: token}, nil
+	case procedurePattern.MatchString(token) && first:
+		return RouteElement{Type: RouteSID, Value: token}, nil
+	case procedurePattern.MatchString(token) && last:
+		return RouteElement{Type: RouteSTAR, Value: token}, nil
+	}
+	return parsePoint(token)
+}
+
+// parsePoint parses a significant point given as a name, as coordinates or as
+// bearing and distance from a named point or coordinates
+func parsePoint(token string) (RouteElement, error) {
+	if namedPointPattern.MatchString(token) {
+		return RouteElement{Type: RoutePoint, Value: token}, nil
+	}
+	if lat, lon, ok := parseLatLong(token); ok {
+		return RouteElement{Type: RoutePoint, Value: token, Lat: lat, Lon: lon}, nil
+	}
+	if m := bearingDistPattern.FindStringSubmatch(token); m != nil {
+		element, err := parsePoint(m[1])
+		bearing, _ := strconv.Atoi(m[2])
+		distance, _ := strconv.Atoi(m[3])
+		if err == nil && element.Bearing == 0 && element.Distance == 0 && bearing <= 360 {
+			element.Bearing = bearing
+			element.Distance = distance
+			return element, nil
+		}
+	}
+	return RouteElement{}, fmt.Errorf("significant point '%s': %w", token, ErrorRouteElement)
+}
+
+// parseLatLong parses coordinates in degrees (46N078W) or degrees and minutes (4620N07805W)
+func parseLatLong(token string) (float64, float64, bool) {
+	m := latLongPattern.FindStringSubmatch(token)
+	if m == nil || (m[2] == "") != (m[5] == "") {
+		return 0, 0, false
+	}
+	lat := degrees(m[1], m[2])
+	lon := degrees(m[4], m[5])
+	if lat > 90 || lon > 180 {
+		return 0, 0, false
+	}
+	if m[3] == "S" {
+		lat = -lat
+	}
+	if m[6] == "W" {
+		lon = -lon
+	}
+	return lat, lon, true
+}
+
+func degrees(deg string, min string) float64 {
+	d, _ := strconv.Atoi(deg)
+	m, _ := strconv.Atoi(min)
+	return float64(d) + float64(m)/60
+}
+
+// RoutePoints builds the ADEXP RTEPTS list from route elements, with each
+// item holding a PT subfield as the ADEXP parser returns it. Each point
+// carries its PTID and the flight level planned from that point on; points
+// along ATS routes are not known without navigation data and are left out.
+func RoutePoints(elements []RouteElement) []interface{} {
+	rtepts := make([]interface{}, 0)
+	level := ""
+	for _, element := range elements {
+		if element.Level != "" {
+			level = element.Level
+		}
+		if element.Type != RoutePoint && element.Type != RouteCruiseClimb {
+			continue
+		}
+		pt := map[string]interface{}{"PTID": element.Value}
+		if element.Bearing != 0 || element.Distance != 0 {
+			pt["PTID"] = fmt.Sprintf("%s%03d%03d", element.Value, element.Bearing, element.Distance)
+		}
+		if level != "" && level != "VFR" {
+			pt["FL"] = level
+		}
+		rtepts = append(rtepts, map[string]interface{}{"PT": pt})
+	}
+	return rtepts
+}
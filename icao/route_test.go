@@ -0,0 +1,84 @@
+package icao
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_ParseRoute(t *testing.T) {
+	route := "N0422F340 LAM1A LAM DCT STG/N0450F360 UN741 4620N07805W DUB180040 VFR C/48N050W/M082F290PLUS IFR KEPER BNN1B"
+	elements, err := ParseRoute(route)
+	if err != nil {
+		t.Fatalf("ParseRoute failed: %v", err)
+	}
+
+	expected := []RouteElement{
+		{Type: RouteSpeedLevel, Value: "N0422F340", Speed: "N0422", Level: "F340"},
+		{Type: RouteSID, Value: "LAM1A"},
+		{Type: RoutePoint, Value: "LAM"},
+		{Type: RouteDirect, Value: "DCT"},
+		{Type: RoutePoint, Value: "STG", Speed: "N0450", Level: "F360"},
+		{Type: RouteATS, Value: "UN741"},
+		{Type: RoutePoint, Value: "4620N07805W", Lat: 46 + 20.0/60, Lon: -(78 + 5.0/60)},
+		{Type: RoutePoint, Value: "DUB", Bearing: 180, Distance: 40},
+		{Type: RouteFlightRules, Value: "VFR"},
+		{Type: RouteCruiseClimb, Value: "48N050W", Lat: 48, Lon: -50, Speed: "M082", Level: "F290", UpperLevel: "PLUS"},
+		{Type: RouteFlightRules, Value: "IFR"},
+		{Type: RoutePoint, Value: "KEPER"},
+		{Type: RouteSTAR, Value: "BNN1B"},
+	}
+	if len(elements) != len(expected) {
+		t.Fatalf("Expected %d elements, got %d: %v", len(expected), len(elements), elements)
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(elements[i], expected[i]) {
+			t.Errorf("Element %d: expected %+v, got %+v", i, expected[i], elements[i])
+		}
+	}
+}
+
+func Test_ParseRoute_Truncation(t *testing.T) {
+	elements, err := ParseRoute("M082F350 UB4 BNE T ANYTHING ?? GOES")
+	if err != nil {
+		t.Fatalf("ParseRoute failed: %v", err)
+	}
+	if len(elements) != 4 || elements[3].Type != RouteTruncation {
+		t.Errorf("Expected route to end with the truncation indicator, got %v", elements)
+	}
+}
+
+func Test_ParseRoute_Errors(t *testing.T) {
+	for _, route := range []string{
+		"",
+		"F340 TURON",
+		"N0422F340 TURON/F360",
+		"N0422F340 C/TURON/N0450F360",
+		"N0422F340 TURON1A UP600 KEPER1A STG",
+		"N0422F340 95N010E",
+		"N0422F340 DUB400040",
+	} {
+		t.Run(route, func(t *testing.T) {
+			_, err := ParseRoute(route)
+			if !errors.Is(err, ErrorRouteElement) {
+				t.Errorf("Expected ErrorRouteElement, got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_RoutePoints(t *testing.T) {
+	elements, err := ParseRoute("N0422F340 TURON UP600 STG/N0450F360 UN741 DUB180040 KEPER")
+	if err != nil {
+		t.Fatalf("ParseRoute failed: %v", err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"PT": map[string]interface{}{"PTID": "TURON", "FL": "F340"}},
+		map[string]interface{}{"PT": map[string]interface{}{"PTID": "STG", "FL": "F360"}},
+		map[string]interface{}{"PT": map[string]interface{}{"PTID": "DUB180040", "FL": "F360"}},
+		map[string]interface{}{"PT": map[string]interface{}{"PTID": "KEPER", "FL": "F360"}},
+	}
+	if rtepts := RoutePoints(elements); !reflect.DeepEqual(rtepts, expected) {
+		t.Errorf("Expected %v, got %v", expected, rtepts)
+	}
+}
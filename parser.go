@@ -0,0 +1,111 @@
+package goflightplan
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/davidkohl/goflightplan/adexp"
+	"github.com/davidkohl/goflightplan/aftn"
+	"github.com/davidkohl/goflightplan/icao"
+)
+
+// Keys of FlightplanWrapper.Meta set by Parser.Parse
+const (
+	// MetaFormat holds the detected MessageType
+	MetaFormat = "format"
+	// MetaEnvelope holds the *aftn.Envelope of AFTN-wrapped messages
+	MetaEnvelope = "envelope"
+	// MetaDiagnostics holds the []error found in a message that parsed
+	// successfully, such as missing mandatory ADEXP fields or item 10 and
+	// item 18 mismatches
+	MetaDiagnostics = "diagnostics"
+)
+
+var ErrorUnknownMessage = errors.New("no ICAO or ADEXP message found")
+
+// icaoMessage matches the opening of an ICAO message, e.g. "(FPL-"
+var icaoMessage = regexp.MustCompile(`\([A-Z]{3}-`)
+
+// Parser parses ICAO and ADEXP messages, with or without AFTN envelope. It is
+// safe for concurrent use.
+type Parser struct {
+	adexp    *adexp.Parser
+	icao     *icao.ICAOParser
+	aftnICAO *icao.ICAOParser
+	opts     icao.ParserOpts
+}
+
+// DefaultParser is used by Parse. It parses ADEXP messages with the standard
+// message set, replace it with NewParser to use custom schemas.
+var DefaultParser = NewParser([]adexp.MessageSet{*adexp.StandardMessageSet()}, icao.ParserOpts{})
+
+// NewParser returns a Parser parsing ADEXP messages with sets and ICAO messages
+// with opts. AFTN envelopes are detected on every message, so opts.AFTNHeader
+// is ignored.
+func NewParser(sets []adexp.MessageSet, opts icao.ParserOpts) *Parser {
+	aftnOpts := opts
+	opts.AFTNHeader = false
+	aftnOpts.AFTNHeader = true
+	return &Parser{
+		adexp:    adexp.NewParser(sets),
+		icao:     icao.NewParser(opts),
+		aftnICAO: icao.NewParser(aftnOpts),
+		opts:     opts,
+	}
+}
+
+// Parse parses a single message with DefaultParser
+func Parse(raw string) (*FlightplanWrapper, error) {
+	return DefaultParser.Parse(raw)
+}
+
+// Parse detects the format of raw, parses it with the matching parser and
+// returns the flight plan with the format, envelope and diagnostics in Meta.
+// Messages of an unsupported type are rejected.
+func (p *Parser) Parse(raw string) (*FlightplanWrapper, error) {
+	fw := NewFlightplanWrapper()
+	fw.Raw = raw
+
+	text := raw
+	var env *aftn.Envelope
+	var err error
+	// Only a leading ZCZC starts an envelope, as in Reader. ZCZC may also
+	// appear in the text of a message, e.g. in a remark.
+	if strings.HasPrefix(strings.TrimLeftFunc(raw, func(c rune) bool { return c <= ' ' }), aftn.StartOfMessage) {
+		env, text, err = aftn.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		fw.Meta[MetaEnvelope] = env
+	}
+
+	format := GetFlightplanFormat(text)
+	fw.Meta[MetaFormat] = format
+
+	var fields map[string]interface{}
+	var diagnostics []error
+	switch {
+	case format == MessageTypeADEXP:
+		fields, diagnostics, err = p.adexp.ParseWithWarnings(text)
+	case format == MessageTypeUnknown:
+		err = ErrorUnknownMessage
+	case env != nil:
+		fields, err = p.aftnICAO.Parse(raw)
+	default:
+		fields, err = p.icao.Parse(text)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if format == MessageTypeICAO && !p.opts.CheckEquipment {
+		if err := icao.CheckEquipment(fields); err != nil {
+			diagnostics = append(diagnostics, err)
+		}
+	}
+	fw.Meta[MetaDiagnostics] = diagnostics
+
+	fw.Flightplan = NewFlightplan(fields)
+	return fw, nil
+}
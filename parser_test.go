@@ -0,0 +1,179 @@
+package goflightplan
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/davidkohl/goflightplan/adexp"
+	"github.com/davidkohl/goflightplan/aftn"
+	"github.com/davidkohl/goflightplan/icao"
+)
+
+func Test_GetFlightplanFormat(t *testing.T) {
+	testCases := []struct {
+		file     string
+		expected MessageType
+	}{
+		{file: "./test/fpl/icao/FPL.txt", expected: MessageTypeICAO},
+		{file: "./test/fpl/icao/CNL.txt", expected: MessageTypeICAO},
+		{file: "./test/fpl/aftn/FPL.txt", expected: MessageTypeICAO},
+		{file: "./test/fpl/adexp/BFD.txt", expected: MessageTypeADEXP},
+		{file: "./test/fpl/adexp/CFD.txt", expected: MessageTypeADEXP},
+		{file: "./test/fpl/adexp/ADEXP_no_title.txt", expected: MessageTypeUnknown},
+		{file: "./test/fpl/adexp/ADEXP_empty.txt", expected: MessageTypeUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			content, err := os.ReadFile(tc.file)
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+			if format := GetFlightplanFormat(string(content)); format != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, format)
+			}
+		})
+	}
+}
+
+func Test_Parser_Parse(t *testing.T) {
+	parser := NewParser([]adexp.MessageSet{loadTestMessageSet(t)}, icao.ParserOpts{})
+
+	testCases := []struct {
+		file        string
+		format      MessageType
+		arcid       string
+		envelope    bool
+		diagnostics int
+	}{
+		{file: "./test/fpl/icao/FPL.txt", format: MessageTypeICAO, arcid: "ABC123", diagnostics: 1},
+		{file: "./test/fpl/icao/FPL_full.txt", format: MessageTypeICAO, arcid: "NAF21", diagnostics: 1},
+		{file: "./test/fpl/icao/CNL.txt", format: MessageTypeICAO, arcid: "WMT912"},
+		{file: "./test/fpl/aftn/FPL.txt", format: MessageTypeICAO, arcid: "ABC123", envelope: true, diagnostics: 1},
+		{file: "./test/fpl/adexp/BFD.txt", format: MessageTypeADEXP, arcid: "DLH151", diagnostics: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			content, err := os.ReadFile(tc.file)
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+			fw, err := parser.Parse(string(content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if fw.Raw != string(content) {
+				t.Errorf("Expected Raw to hold the message")
+			}
+			if fw.Meta[MetaFormat] != tc.format {
+				t.Errorf("Expected format %v, got %v", tc.format, fw.Meta[MetaFormat])
+			}
+			if fw.Flightplan.ARCID != tc.arcid {
+				t.Errorf("Expected ARCID %s, got %s", tc.arcid, fw.Flightplan.ARCID)
+			}
+			if _, ok := fw.Meta[MetaEnvelope].(*aftn.Envelope); ok != tc.envelope {
+				t.Errorf("Expected envelope %v, got %v", tc.envelope, fw.Meta[MetaEnvelope])
+			}
+			diagnostics, ok := fw.Meta[MetaDiagnostics].([]error)
+			if !ok || len(diagnostics) != tc.diagnostics {
+				t.Errorf("Expected %d diagnostics, got %v", tc.diagnostics, fw.Meta[MetaDiagnostics])
+			}
+		})
+	}
+}
+
+func Test_Parser_Parse_Mandatory(t *testing.T) {
+	parser := NewParser([]adexp.MessageSet{loadTestMessageSet(t)}, icao.ParserOpts{})
+	fw, err := parser.Parse("-TITLE BFD -REFDATA -SENDER -FAC EBBUZXZQ -RECVR -FAC EBSZZXZQ -SEQNUM 006 -ADEP EDDW")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	diagnostics, _ := fw.Meta[MetaDiagnostics].([]error)
+	var missing *adexp.MissingFieldError
+	if len(diagnostics) != 1 || !errors.As(diagnostics[0], &missing) || missing.Path != "ARCID" {
+		t.Errorf("Expected ARCID to be reported missing, got %v", diagnostics)
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	content, err := os.ReadFile("./test/fpl/icao/FPL_full.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	fw, err := Parse(string(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if fw.Flightplan.ARCID != "NAF21" || fw.Flightplan.ADES != "EDDF" {
+		t.Errorf("Unexpected flight plan: %+v", fw.Flightplan)
+	}
+
+	fw, err = Parse("-TITLE SLC -ARCID AMC101 -ADEP EGLL -ADES LMML -EOBT 0945 -REASON VOID")
+	if err != nil {
+		t.Fatalf("Parse of ADEXP message failed: %v", err)
+	}
+	if fw.Flightplan.ARCID != "AMC101" || fw.Flightplan.EOBT != "0945" {
+		t.Errorf("Unexpected flight plan: %+v", fw.Flightplan)
+	}
+
+	if _, err := Parse("NOT A FLIGHT PLAN"); !errors.Is(err, ErrorUnknownMessage) {
+		t.Errorf("Expected %v, got %v", ErrorUnknownMessage, err)
+	}
+}
+
+func Test_Parse_Malformed(t *testing.T) {
+	testCases := []struct {
+		message  string
+		expected error
+		code     adexp.ErrorCode
+	}{
+		{message: "(XYZ-ABC-DEF)", expected: icao.ErrorUnsupportedMessage},
+		{message: "x) (FPL-A", expected: icao.ErrorNoClosingBracket},
+		{message: "(CNL-WMT912-EDJA201)-LIR)-DOF/24", expected: icao.ErrorItemInvalid},
+		{message: "-TITLE IFPL -ARCID X -BEGIN", code: adexp.CodeInvalidField},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			fw, err := Parse(tc.message)
+			if err == nil || fw != nil {
+				t.Fatalf("Expected an error, got %+v", fw)
+			}
+			if tc.expected != nil && !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+			var parseErr *adexp.ParseError
+			if tc.code != "" && (!errors.As(err, &parseErr) || parseErr.Code != tc.code) {
+				t.Errorf("Expected a %s error, got %v", tc.code, err)
+			}
+		})
+	}
+}
+
+func Test_Parse_ZCZCInText(t *testing.T) {
+	for _, message := range []string{
+		"(CNL-WMT912-EDJA2010-LIRF-DOF/240228 RMK/ZCZC)",
+		"-TITLE SAM -ARCID AMC101 -COMMENT ZCZC",
+	} {
+		fw, err := Parse(message)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if _, ok := fw.Meta[MetaEnvelope]; ok {
+			t.Errorf("Expected no envelope for %q", message)
+		}
+	}
+}
+
+func Test_MessageType_String(t *testing.T) {
+	for format, expected := range map[MessageType]string{
+		MessageTypeICAO:    "ICAO",
+		MessageTypeADEXP:   "ADEXP",
+		MessageTypeUnknown: "UNKNOWN",
+	} {
+		if format.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, format.String())
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package goflightplan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/davidkohl/goflightplan/aftn"
+)
+
+// readSize is the number of bytes requested from the underlying reader at a time
+const readSize = 4096
+
+// DefaultMaxMessageSize is the MaxMessageSize of the Readers returned by NewReader
+const DefaultMaxMessageSize = 64 * 1024
+
+var ErrorMessageTooLarge = errors.New("message too large")
+
+// icaoStart matches the opening of a bare ICAO message, e.g. "(FPL-"
+var icaoStart = regexp.MustCompile(`^\([A-Z]{3}-`)
+
+// Message is a single message read from a stream
+type Message struct {
+	// Offset is the byte offset of Raw in the stream
+	Offset int64
+	// Raw is the text of the message. It is empty for messages dropped
+	// with ErrorMessageTooLarge.
+	Raw        string
+	Flightplan *FlightplanWrapper
+	// Err is set if the message could not be parsed. The Reader continues
+	// with the next message.
+	Err error
+}
+
+// Reader reads consecutive AFTN, ADEXP and ICAO messages from a stream such as
+// a socket or a log file. Messages are split at ZCZC/NNNN, at -TITLE and at
+// the opening parenthesis of a bare ICAO message.
+type Reader struct {
+	// MaxMessageSize is the number of bytes a message may span. Longer
+	// messages, e.g. a ZCZC never closed by NNNN, are dropped up to the next
+	// message start and reported with ErrorMessageTooLarge.
+	MaxMessageSize int
+
+	r      io.Reader
+	parser *Parser
+	data   []byte
+	pos    int
+	offset int64
+	eof    bool
+	chunk  [readSize]byte
+	// dropping is set while a message exceeding MaxMessageSize is skipped,
+	// dropStart holds its offset
+	dropping  bool
+	dropStart int64
+}
+
+// NewReader returns a Reader parsing messages with p, or with DefaultParser if
+// p is nil
+func NewReader(r io.Reader, p *Parser) *Reader {
+	if p == nil {
+		p = DefaultParser
+	}
+	return &Reader{MaxMessageSize: DefaultMaxMessageSize, r: r, parser: p}
+}
+
+// Next returns the next message of the stream, or io.EOF once the stream is
+// exhausted. An ADEXP message without NNNN ends where the next message starts,
+// so it is only returned once that message or the end of the stream is read.
+// Errors of the underlying reader are returned as is; parse errors are
+// reported in Message.Err.
+func (r *Reader) Next() (*Message, error) {
+	for {
+		if !r.dropping {
+			if msg, ok := r.next(); ok {
+				return msg, nil
+			}
+		}
+		if r.dropping {
+			if msg := r.drop(); msg != nil {
+				return msg, nil
+			}
+		} else if r.eof {
+			return nil, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fill reads the next chunk of the stream, dropping the consumed part of the buffer
+func (r *Reader) fill() error {
+	if r.pos > 0 {
+		r.data = r.data[:copy(r.data, r.data[r.pos:])]
+		r.pos = 0
+	}
+	n, err := r.r.Read(r.chunk[:])
+	r.data = append(r.data, r.chunk[:n]...)
+	if errors.Is(err, io.EOF) {
+		r.eof = true
+		return nil
+	}
+	return err
+}
+
+// skipSeparators drops whitespace and control characters between messages
+func (r *Reader) skipSeparators() {
+	for r.pos < len(r.data) && r.data[r.pos] <= ' ' {
+		r.pos++
+		r.offset++
+	}
+}
+
+// next returns the message at the start of the buffer if it is complete.
+// Once the message exceeds MaxMessageSize, the Reader starts dropping it.
+func (r *Reader) next() (*Message, bool) {
+	r.skipSeparators()
+	buf := r.data[r.pos:]
+	if len(buf) == 0 {
+		return nil, false
+	}
+	n, ok := messageEnd(buf)
+	switch {
+	case ok && n <= r.MaxMessageSize:
+		return r.emit(n), true
+	case ok:
+		r.dropStart = r.offset
+		return r.tooLarge(n), true
+	case len(buf) > r.MaxMessageSize:
+		r.dropping, r.dropStart = true, r.offset
+	case r.eof:
+		return r.emit(len(buf)), true
+	}
+	return nil, false
+}
+
+// drop discards the buffer up to the next message start. It returns the
+// error of the dropped message once that start or the end of the stream is
+// found, and nil if more data is needed.
+func (r *Reader) drop() *Message {
+	buf := r.data[r.pos:]
+	if n := nextBoundary(buf, 1); n != -1 {
+		return r.tooLarge(n)
+	}
+	if r.eof {
+		return r.tooLarge(len(buf))
+	}
+	// Keep the bytes which may hold the beginning of the next message start
+	// and the separator before it
+	if keep := len("-TITLE") + 1; len(buf) > keep {
+		r.consume(len(buf) - keep)
+	}
+	return nil
+}
+
+// tooLarge consumes the last n bytes of a message exceeding MaxMessageSize
+// and reports it
+func (r *Reader) tooLarge(n int) *Message {
+	r.consume(n)
+	r.dropping = false
+	return &Message{
+		Offset: r.dropStart,
+		Err:    fmt.Errorf("%w: more than %d bytes at offset %d", ErrorMessageTooLarge, r.MaxMessageSize, r.dropStart),
+	}
+}
+
+// emit consumes the next n bytes of the buffer and parses them as a message
+func (r *Reader) emit(n int) *Message {
+	raw := strings.TrimRightFunc(string(r.data[r.pos:r.pos+n]), func(c rune) bool { return c <= ' ' })
+	msg := &Message{Offset: r.offset, Raw: raw}
+	r.consume(n)
+	msg.Flightplan, msg.Err = r.parser.Parse(raw)
+	return msg
+}
+
+// consume drops the next n bytes of the buffer
+func (r *Reader) consume(n int) {
+	r.pos += n
+	r.offset += int64(n)
+}
+
+// messageEnd returns the length of the message at the start of buf and
+// whether it is complete
+func messageEnd(buf []byte) (int, bool) {
+	switch {
+	case bytes.HasPrefix(buf, []byte(aftn.StartOfMessage)):
+		if end := bytes.Index(buf, []byte(aftn.EndOfMessage)); end != -1 {
+			return end + len(aftn.EndOfMessage), true
+		}
+		return 0, false
+	case icaoStart.Match(buf):
+		depth := 0
+		for i, c := range buf {
+			switch c {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return i + 1, true
+				}
+			}
+		}
+		return 0, false
+	}
+	// ADEXP messages and anything unrecognised run up to the next message
+	if next := nextBoundary(buf, 1); next != -1 {
+		return next, true
+	}
+	return 0, false
+}
+
+// nextBoundary returns the index of the first message start in buf at or after
+// from, or -1. A message start must follow whitespace or a control character.
+func nextBoundary(buf []byte, from int) int {
+	for i := from; i < len(buf); i++ {
+		if buf[i-1] > ' ' {
+			continue
+		}
+		rest := buf[i:]
+		if bytes.HasPrefix(rest, []byte(aftn.StartOfMessage)) ||
+			bytes.HasPrefix(rest, []byte("-TITLE")) ||
+			icaoStart.Match(rest) {
+			return i
+		}
+	}
+	return -1
+}
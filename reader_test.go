@@ -0,0 +1,181 @@
+package goflightplan
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/davidkohl/goflightplan/adexp"
+	"github.com/davidkohl/goflightplan/aftn"
+	"github.com/davidkohl/goflightplan/icao"
+)
+
+func Test_Reader(t *testing.T) {
+	content, err := os.ReadFile("./test/fpl/stream/feed.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	testSchema := []adexp.MessageSet{loadTestMessageSet(t)}
+
+	expected := []struct {
+		title string
+		arcid string
+		err   error
+	}{
+		{title: "FPL", arcid: "ABC123"},
+		{title: "DES", arcid: "AMC101"},
+		{title: "CNL", arcid: "WMT912"},
+		{err: ErrorUnknownMessage},
+		{title: "DES", arcid: "AMC102"},
+		{title: "DLA", arcid: "WZZ5322"},
+	}
+
+	readers := map[string]io.Reader{
+		"whole":    strings.NewReader(string(content)),
+		"one byte": iotest.OneByteReader(strings.NewReader(string(content))),
+		"half":     iotest.HalfReader(strings.NewReader(string(content))),
+	}
+
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			reader := NewReader(r, NewParser(testSchema, icao.ParserOpts{}))
+			for i, exp := range expected {
+				msg, err := reader.Next()
+				if err != nil {
+					t.Fatalf("message %d: Next failed: %v", i, err)
+				}
+				if int(msg.Offset)+len(msg.Raw) > len(content) || string(content[msg.Offset:int(msg.Offset)+len(msg.Raw)]) != msg.Raw {
+					t.Errorf("message %d: Raw does not match the stream at offset %d", i, msg.Offset)
+				}
+				if exp.err != nil {
+					if !errors.Is(msg.Err, exp.err) {
+						t.Errorf("message %d: expected error %v, got %v", i, exp.err, msg.Err)
+					}
+					continue
+				}
+				if msg.Err != nil {
+					t.Fatalf("message %d: unexpected error: %v", i, msg.Err)
+				}
+				if msg.Flightplan.Flightplan.TITLE != exp.title || msg.Flightplan.Flightplan.ARCID != exp.arcid {
+					t.Errorf("message %d: expected %s %s, got %s %s", i, exp.title, exp.arcid,
+						msg.Flightplan.Flightplan.TITLE, msg.Flightplan.Flightplan.ARCID)
+				}
+			}
+			if _, err := reader.Next(); err != io.EOF {
+				t.Errorf("Expected io.EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_Reader_Envelope(t *testing.T) {
+	content, err := os.ReadFile("./test/fpl/aftn/FPL.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	reader := NewReader(strings.NewReader(string(content)), nil)
+	msg, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	env, ok := msg.Flightplan.Meta[MetaEnvelope].(*aftn.Envelope)
+	if !ok {
+		t.Fatalf("Expected the envelope in Meta, got %v", msg.Flightplan.Meta)
+	}
+	if env.Originator != "LPPRZPZX" || msg.Flightplan.Flightplan.REFDATA.SENDER != "LPPRZPZX" {
+		t.Errorf("Unexpected originator: %s, %s", env.Originator, msg.Flightplan.Flightplan.REFDATA.SENDER)
+	}
+}
+
+func Test_Reader_Truncated(t *testing.T) {
+	reader := NewReader(strings.NewReader("ZCZC ABC001\nGG EDDFZQZX\n010000 EDDMZQZX\n(CNL-WMT912"), nil)
+	msg, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if !errors.Is(msg.Err, aftn.ErrorNoEndOfMessage) {
+		t.Errorf("Expected %v, got %v", aftn.ErrorNoEndOfMessage, msg.Err)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func Test_Reader_Malformed(t *testing.T) {
+	reader := NewReader(strings.NewReader("-TITLE IFPL -ARCID X -BEGIN\n(XYZ-ABC-DEF)\n(CNL-WMT912-EDJA2010-LIRF-DOF/240228)\n"), nil)
+	for i, expected := range []error{nil, icao.ErrorUnsupportedMessage} {
+		msg, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if msg.Err == nil || (expected != nil && !errors.Is(msg.Err, expected)) {
+			t.Errorf("Expected message %d to be rejected with %v, got %v", i, expected, msg.Err)
+		}
+	}
+	msg, err := reader.Next()
+	if err != nil || msg.Err != nil || msg.Flightplan.Flightplan.ARCID != "WMT912" {
+		t.Errorf("Expected the reader to continue after malformed messages, got %+v %v", msg, err)
+	}
+}
+
+func Test_Reader_TooLarge(t *testing.T) {
+	long := strings.Repeat("A ", 100)
+	cnl := "(CNL-WMT912-EDJA2010-LIRF-DOF/240228)"
+	testCases := []struct {
+		name   string
+		stream string
+		// next is the ARCID of the message expected after the dropped one,
+		// empty if the stream ends
+		next string
+	}{
+		{name: "ZCZC without NNNN", stream: "\n\nZCZC ABC001\nGG EDDFZQZX\n" + long + "\n" + cnl, next: "WMT912"},
+		{name: "bracket not closed", stream: "\n\n(FPL-ABC123 " + long + "\n" + cnl, next: "WMT912"},
+		{name: "complete message", stream: "\n\n(CNL-" + long + ")\n" + cnl, next: "WMT912"},
+		{name: "end of stream", stream: "\n\n(FPL-ABC123 " + long},
+	}
+
+	readers := map[string]func(string) io.Reader{
+		"whole":    func(s string) io.Reader { return strings.NewReader(s) },
+		"one byte": func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+	}
+
+	for _, tc := range testCases {
+		for name, newReader := range readers {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				reader := NewReader(newReader(tc.stream), nil)
+				reader.MaxMessageSize = 64
+				msg, err := reader.Next()
+				if err != nil {
+					t.Fatalf("Next failed: %v", err)
+				}
+				if !errors.Is(msg.Err, ErrorMessageTooLarge) || msg.Offset != 2 {
+					t.Fatalf("Expected %v at offset 2, got %v at offset %d", ErrorMessageTooLarge, msg.Err, msg.Offset)
+				}
+				if !strings.Contains(msg.Err.Error(), "at offset 2") {
+					t.Errorf("Expected the error to name the offset, got %v", msg.Err)
+				}
+				if tc.next != "" {
+					msg, err = reader.Next()
+					if err != nil || msg.Err != nil || msg.Flightplan.Flightplan.ARCID != tc.next {
+						t.Fatalf("Expected the reader to resynchronise on %s, got %+v %v", tc.next, msg, err)
+					}
+					if msg.Raw != cnl {
+						t.Errorf("Expected %q, got %q", cnl, msg.Raw)
+					}
+				}
+				if _, err := reader.Next(); err != io.EOF {
+					t.Errorf("Expected io.EOF, got %v", err)
+				}
+				if len(reader.data) > reader.MaxMessageSize+readSize {
+					t.Errorf("Expected the buffer to stay below %d bytes, got %d", reader.MaxMessageSize+readSize, len(reader.data))
+				}
+			})
+		}
+	}
+}